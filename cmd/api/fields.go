@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"greenlight.aenkas.org/internal/validator"
+)
+
+type fieldSelection struct {
+	fields  []string
+	include []string
+}
+
+func (app *application) readFieldSelection(qs url.Values, resource interface{}, includable []string, v *validator.Validator) fieldSelection {
+	fs := fieldSelection{
+		fields:  app.readCSV(qs, "fields", []string{}),
+		include: app.readCSV(qs, "include", []string{}),
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(resource))
+
+	for _, field := range fs.fields {
+		if !validator.In(field, known...) {
+			v.AddError("fields", fmt.Sprintf("unknown field %q, must be one of %s", field, strings.Join(known, ", ")))
+		}
+	}
+
+	for _, name := range fs.include {
+		if !validator.In(name, includable...) {
+			v.AddError("include", fmt.Sprintf("unknown include %q, must be one of %s", name, strings.Join(includable, ", ")))
+		}
+	}
+
+	return fs
+}
+
+func (fs fieldSelection) includes(name string) bool {
+	return validator.In(name, fs.include...)
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	names := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		switch {
+		case name == "-":
+			continue
+		case name == "" && field.Anonymous:
+			names = append(names, jsonFieldNames(field.Type)...)
+		case name == "":
+			names = append(names, field.Name)
+		default:
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// project takes one related value per resource under e[key], in the same
+// order, for each include name.
+func (e envelope) project(key string, fs fieldSelection, related map[string][]interface{}) error {
+	if len(fs.fields) == 0 && len(related) == 0 {
+		return nil
+	}
+
+	items, single, err := e.items(key)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if len(fs.fields) > 0 {
+			for name := range item {
+				if !validator.In(name, fs.fields...) {
+					delete(item, name)
+				}
+			}
+		}
+
+		for name, values := range related {
+			if values[i] == nil {
+				continue
+			}
+
+			js, err := json.Marshal(values[i])
+			if err != nil {
+				return err
+			}
+			item[name] = js
+		}
+	}
+
+	e.setItems(key, items, single)
+
+	return nil
+}
+
+// format leaves out resources the field was left out of, by a field selection
+// or by omitempty.
+func (e envelope) format(key, field string, values []interface{}) error {
+	items, single, err := e.items(key)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if _, ok := item[field]; !ok {
+			continue
+		}
+
+		js, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		item[field] = js
+	}
+
+	e.setItems(key, items, single)
+
+	return nil
+}
+
+func (e envelope) items(key string) ([]map[string]json.RawMessage, bool, error) {
+	js, err := json.Marshal(e[key])
+	if err != nil {
+		return nil, false, err
+	}
+
+	var items []map[string]json.RawMessage
+
+	single := len(js) > 0 && js[0] == '{'
+	if single {
+		js = append(append([]byte{'['}, js...), ']')
+	}
+
+	err = json.Unmarshal(js, &items)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return items, single, nil
+}
+
+func (e envelope) setItems(key string, items []map[string]json.RawMessage, single bool) {
+	if single {
+		e[key] = items[0]
+	} else {
+		e[key] = items
+	}
+}
@@ -27,6 +27,17 @@ func (app *application) readIDParam(r *http.Request) (int64, error) {
 	return id, nil
 }
 
+func (app *application) readVersionParam(r *http.Request) (int32, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	version, err := strconv.ParseInt(params.ByName("version"), 10, 32)
+	if err != nil || version < 1 {
+		return 0, errors.New("invalid version param")
+	}
+
+	return int32(version), nil
+}
+
 func (app *application) writeJSON(w http.ResponseWriter, status int, data envelope, headers http.Header) error {
 	js, err := json.Marshal(data)
 	if err != nil {
@@ -99,6 +110,58 @@ func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst int
 	return nil
 }
 
+func (app *application) readUpload(w http.ResponseWriter, r *http.Request, field string, maxBytes int64) ([]byte, error) {
+	// Leave room for multipart boundaries and part headers.
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+64*1024)
+
+	var src io.Reader = r.Body
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				switch {
+				case errors.Is(err, io.EOF):
+					return nil, fmt.Errorf("body must contain a %q file", field)
+				case err.Error() == "http: request body too large":
+					return nil, fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+				default:
+					return nil, err
+				}
+			}
+
+			if part.FormName() == field {
+				src = part
+				break
+			}
+		}
+	}
+
+	b, err := io.ReadAll(io.LimitReader(src, maxBytes+1))
+	if err != nil {
+		switch {
+		case err.Error() == "http: request body too large":
+			return nil, fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+		default:
+			return nil, err
+		}
+	}
+
+	switch {
+	case len(b) == 0:
+		return nil, errors.New("body must not be empty")
+	case int64(len(b)) > maxBytes:
+		return nil, fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+	}
+
+	return b, nil
+}
+
 func (app *application) readString(qs url.Values, key string, defaultValue string) string {
 	s := qs.Get(key)
 	if s == "" {
@@ -115,6 +178,21 @@ func (app *application) readCSV(qs url.Values, key string, defaultValue []string
 	return strings.Split(csv, ",")
 }
 
+func (app *application) readIDList(qs url.Values, key string, v *validator.Validator) []int64 {
+	ids := []int64{}
+
+	for _, s := range app.readCSV(qs, key, []string{}) {
+		id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			v.AddError(key, "must be a comma-separated list of integers")
+			return nil
+		}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
 func (app *application) readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
 	s := qs.Get(key)
 	if s == "" {
@@ -130,6 +208,60 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 	return i
 }
 
+func (app *application) readBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	s := qs.Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+
+	return b
+}
+
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (app *application) checkIfMatch(w http.ResponseWriter, r *http.Request, etag string) bool {
+	ifMatch := r.Header.Get("If-Match")
+
+	if ifMatch == "" {
+		if app.config.requireIfMatch {
+			app.preconditionRequiredResponse(w, r)
+			return false
+		}
+		return true
+	}
+
+	if !etagMatches(ifMatch, etag, false) {
+		app.preconditionFailedResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
 func (app *application) background(fn func()) {
 	app.wg.Add(1)
 
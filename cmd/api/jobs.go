@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"greenlight.aenkas.org/internal/data"
+)
+
+func (app *application) schedule(ctx context.Context, name string, interval time.Duration, runAtStart bool, fn func() error) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+
+		if runAtStart {
+			app.runJob(name, fn)
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				app.runJob(name, fn)
+			}
+		}
+	}()
+}
+
+func (app *application) runJob(name string, fn func() error) {
+	defer func() {
+		if err := recover(); err != nil {
+			app.logger.PrintError(fmt.Errorf("%s", err), map[string]string{"job": name})
+		}
+	}()
+
+	err := fn()
+	if err != nil {
+		app.logger.PrintError(err, map[string]string{"job": name})
+	}
+}
+
+func (app *application) purgeDeletedMovies() error {
+	posters, err := app.models.Movies.PurgeDeleted(app.config.trash.retention)
+	if err != nil {
+		return err
+	}
+
+	for _, urls := range posters {
+		app.deletePosterFiles(urls, nil)
+	}
+
+	if len(posters) > 0 {
+		app.logger.PrintInfo("purged deleted movies", map[string]string{
+			"count": strconv.Itoa(len(posters)),
+		})
+	}
+
+	return nil
+}
+
+func (app *application) rebuildMovieNeighbours() error {
+	movies, coRatings, err := app.models.MovieNeighbours.GetInputs(3, 5*maxSimilarMovies)
+	if err != nil {
+		return err
+	}
+
+	neighbours := data.ComputeNeighbours(movies, coRatings, app.similarityScorer(coRatings), maxSimilarMovies)
+
+	err = app.models.MovieNeighbours.Replace(neighbours)
+	if err != nil {
+		return err
+	}
+
+	app.logger.PrintInfo("rebuilt movie neighbours", map[string]string{
+		"movies":     strconv.Itoa(len(movies)),
+		"neighbours": strconv.Itoa(len(neighbours)),
+	})
+
+	return nil
+}
+
+func (app *application) sendSavedSearchDigests() error {
+	return app.sendSavedSearchAlerts("daily")
+}
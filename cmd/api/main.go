@@ -16,6 +16,8 @@ import (
 	"greenlight.aenkas.org/internal/data"
 	"greenlight.aenkas.org/internal/jsonlog"
 	"greenlight.aenkas.org/internal/mailer"
+	"greenlight.aenkas.org/internal/storage"
+	"greenlight.aenkas.org/internal/validator"
 )
 
 var (
@@ -47,14 +49,33 @@ type config struct {
 	cors struct {
 		trustedOrigins []string
 	}
+	trash struct {
+		retention time.Duration
+	}
+	requireIfMatch bool
+	storage        struct {
+		dir string
+		url string
+	}
+	similarity struct {
+		strategy string
+	}
+	stats struct {
+		ttl time.Duration
+	}
 }
 
 type application struct {
-	config config
-	logger *jsonlog.Logger
-	models *data.Models
-	mailer mailer.Mailer
-	wg     sync.WaitGroup
+	config  config
+	logger  *jsonlog.Logger
+	models  *data.Models
+	mailer  mailer.Mailer
+	storage storage.Storage
+	wg      sync.WaitGroup
+
+	stopJobs   context.CancelFunc
+	statsCache statsCache
+	alertsMu   sync.Mutex
 }
 
 func main() {
@@ -86,6 +107,24 @@ func main() {
 		return nil
 	})
 
+	flag.DurationVar(&cfg.trash.retention, "trash-retention", 30*24*time.Hour, "Retention period for deleted movies before they are purged")
+
+	flag.BoolVar(&cfg.requireIfMatch, "require-if-match", false, "Require an If-Match header on movie writes")
+
+	flag.StringVar(&cfg.storage.dir, "storage-dir", "./uploads", "Directory uploaded files are stored in")
+	flag.StringVar(&cfg.storage.url, "storage-url", "http://localhost:4000/uploads", "Public URL the storage directory is served from")
+
+	cfg.similarity.strategy = "blended"
+	flag.Func("similarity-strategy", "Similar movies scoring strategy (features|blended)", func(val string) error {
+		if !validator.In(val, similarityStrategies...) {
+			return fmt.Errorf("must be one of %s", strings.Join(similarityStrategies, ", "))
+		}
+		cfg.similarity.strategy = val
+		return nil
+	})
+
+	flag.DurationVar(&cfg.stats.ttl, "stats-ttl", time.Minute, "How long catalogue statistics are cached for")
+
 	displayVersion := flag.Bool("version", false, "Display version and exit")
 
 	flag.Parse()
@@ -118,13 +157,27 @@ func main() {
 		return time.Now().Unix()
 	}))
 
+	store, err := storage.NewLocal(cfg.storage.dir, cfg.storage.url)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+
 	app := &application{
-		config: cfg,
-		logger: logger,
-		models: data.NewModels(db),
-		mailer: mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		config:   cfg,
+		logger:   logger,
+		models:   data.NewModels(db),
+		mailer:   mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		storage:  store,
+		stopJobs: stopJobs,
 	}
 
+	app.schedule(jobsCtx, "purge_deleted_movies", time.Hour, false, app.purgeDeletedMovies)
+	app.schedule(jobsCtx, "rebuild_movie_neighbours", 24*time.Hour, true, app.rebuildMovieNeighbours)
+	app.schedule(jobsCtx, "match_saved_searches", time.Minute, false, app.matchSavedSearches)
+	app.schedule(jobsCtx, "send_saved_search_digests", 24*time.Hour, false, app.sendSavedSearchDigests)
+
 	err = app.serve()
 	if err != nil {
 		logger.PrintFatal(err, nil)
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"greenlight.aenkas.org/internal/data"
@@ -12,45 +15,166 @@ import (
 
 func (app *application) getMoviesHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title  string
-		Genres []string
+		data.MovieFilters
 		data.ListParams
 	}
 
 	v := validator.New()
 	qs := r.URL.Query()
 
-	input.Title = app.readString(qs, "title", "")
-	input.Genres = app.readCSV(qs, "genres", []string{})
+	out := app.readMovieOutput(r, v)
+
+	if qs.Has("ids") {
+		ids := app.readIDList(qs, "ids", v)
+
+		if data.ValidateMovieIDs(v, ids); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		app.writeMoviesByIDs(w, r, ids, out)
+		return
+	}
+
+	var err error
+
+	input.MovieFilters, err = app.readMovieFilters(qs, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 
 	input.ListParams.Page = app.readInt(qs, "page", 1, v)
 	input.ListParams.PageSize = app.readInt(qs, "pageSize", 20, v)
 	input.ListParams.Sort = app.readString(qs, "sort", "id")
-	input.ListParams.SortSafelist = []string{"id", "title", "year", "runtime", "-id", "-title", "-year", "-runtime"}
+	input.ListParams.SortSafelist = []string{"id", "title", "year", "runtime", "average_rating", "relevance", "-id", "-title", "-year", "-runtime", "-average_rating"}
 
-	if data.ValidateListParams(v, input.ListParams); !v.Valid() {
-		app.failedValidationResponse(w, r, v.Errors)
+	v.Check(input.ListParams.Sort != "relevance" || input.MovieFilters.Title != "", "sort", "relevance sort requires a title search")
+
+	var movies []*data.Movie
+	env := envelope{}
+
+	// A title that no movie matches as typed is most likely a typo, so fall
+	// back to trigram matching and suggest the closest titles. Only the title
+	// decides this, not the other filters or the page.
+	if input.MovieFilters.Title != "" && v.Valid() {
+		found, err := app.models.Movies.HasTitleMatches(input.MovieFilters)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !found {
+			input.MovieFilters.Fuzzy = true
+
+			suggestions, err := app.models.Movies.GetSuggestions(input.MovieFilters.Title, 5)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			env["suggestions"] = suggestions
+		}
+	}
+
+	if qs.Has("cursor") {
+		input.ListParams.Cursor = qs.Get("cursor")
+
+		v.Check(input.ListParams.Sort != "relevance", "sort", "relevance sort is not supported with a cursor")
+
+		if data.ValidateCursorListParams(v, input.ListParams); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		var metadata data.CursorMetadata
+
+		movies, metadata, err = app.models.Movies.GetManyByCursor(input.MovieFilters, input.ListParams)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		env["metadata"] = metadata
+	} else {
+		if data.ValidateListParams(v, input.ListParams); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		var metadata data.Metadata
+
+		movies, metadata, err = app.models.Movies.GetMany(input.MovieFilters, input.ListParams)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		env["metadata"] = metadata
+	}
+
+	facets, err := app.models.Movies.GetFacets(input.MovieFilters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	movies, metadata, err := app.models.Movies.GetMany(input.Title, input.Genres, input.ListParams)
+	env["movies"], env["facets"] = movies, facets
+
+	_, err = app.localizeMovies(w, out.locales, movies)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"movies": movies, "metadata": metadata}, nil)
+	err = app.projectMovies(env, "movies", movies, out)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+func (app *application) readMovieFilters(qs url.Values, v *validator.Validator) (data.MovieFilters, error) {
+	genres, err := app.models.Genres.GetTaxonomy()
+	if err != nil {
+		return data.MovieFilters{}, err
+	}
+
+	return app.parseMovieFilters(qs, genres, v), nil
+}
+
+func (app *application) parseMovieFilters(qs url.Values, genres data.GenreTaxonomy, v *validator.Validator) data.MovieFilters {
+	f := data.MovieFilters{
+		Title:          app.readString(qs, "title", ""),
+		Genres:         app.readCSV(qs, "genres", []string{}),
+		GenresMode:     app.readString(qs, "genres_mode", "all"),
+		ExcludedGenres: app.readCSV(qs, "-genres", []string{}),
+		YearFrom:       app.readInt(qs, "year_from", 0, v),
+		YearTo:         app.readInt(qs, "year_to", 0, v),
+		RuntimeMin:     app.readInt(qs, "runtime_min", 0, v),
+		RuntimeMax:     app.readInt(qs, "runtime_max", 0, v),
+		PersonID:       int64(app.readInt(qs, "person", 0, v)),
+		Language:       app.readString(qs, "language", "simple"),
+	}
+
+	data.ValidateMovieFilters(v, f, genres)
+
+	return f
+}
+
 func (app *application) createMovieHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Title   string       `json:"title"`
-		Year    int32        `json:"year"`
-		Runtime data.Runtime `json:"runtime"`
-		Genres  []string     `json:"genres"`
+		Title          string           `json:"title"`
+		Year           int32            `json:"year"`
+		Runtime        data.Runtime     `json:"runtime"`
+		Genres         []string         `json:"genres"`
+		ExternalIDs    data.ExternalIDs `json:"external_ids"`
+		OriginalLocale string           `json:"original_locale"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -60,29 +184,61 @@ func (app *application) createMovieHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	movie := &data.Movie{
-		Title:   input.Title,
-		Year:    input.Year,
-		Runtime: input.Runtime,
-		Genres:  input.Genres,
+		Title:          input.Title,
+		Year:           input.Year,
+		Runtime:        input.Runtime,
+		Genres:         input.Genres,
+		ExternalIDs:    input.ExternalIDs,
+		OriginalLocale: input.OriginalLocale,
+	}
+
+	genres, err := app.models.Genres.GetTaxonomy()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
 
 	v := validator.New()
 
-	if data.ValidateMovie(v, movie); !v.Valid() {
+	qs := r.URL.Query()
+	force := app.readBool(qs, "force", false, v)
+	fuzzy := app.readBool(qs, "fuzzy", false, v)
+
+	if data.ValidateMovie(v, movie, genres); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	err = app.models.Movies.Insert(movie)
+	if !force {
+		duplicates, err := app.models.Movies.FindDuplicates(movie, fuzzy)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if len(duplicates) > 0 {
+			app.duplicateMovieResponse(w, r, duplicates)
+			return
+		}
+	}
+
+	err = app.models.Movies.Insert(movie, app.contextGetUser(r).ID)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrDuplicateExternalID):
+			v.AddError("external_ids", "an external ID is already assigned to another movie")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
 
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/movies/%d", movie.ID))
+	headers.Set("ETag", movie.ETag())
 
-	app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	err = app.writeJSON(w, http.StatusCreated, envelope{"movie": movie}, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -95,18 +251,55 @@ func (app *application) getMovieHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	v := validator.New()
+
+	out := app.readMovieOutput(r, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
 	movie, err := app.models.Movies.Get(id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
-			app.notFoundResponse(w, r)
+			app.redirectMergedMovie(w, r, id)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	translated, err := app.localizeMovies(w, out.locales, []*data.Movie{movie})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+
+	// Included data and translations change without bumping the version.
+	if len(out.include) == 0 && !translated {
+		etag := movie.ETag()
+
+		if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag, true) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		headers.Set("ETag", etag)
+	}
+
+	env := envelope{"movie": movie}
+
+	err = app.projectMovies(env, "movie", []*data.Movie{movie}, out)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -137,53 +330,116 @@ func (app *application) updateMovieHandler(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	var input struct {
-		Title   *string       `json:"title"`
-		Year    *int32        `json:"year"`
-		Runtime *data.Runtime `json:"runtime"`
-		Genres  []string      `json:"genres"`
+	if !app.checkIfMatch(w, r, movie.ETag()) {
+		return
 	}
 
-	err = app.readJSON(w, r, &input)
+	genres, err := app.models.Genres.GetTaxonomy()
 	if err != nil {
-		app.badRequestResponse(w, r, err)
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	if input.Title != nil {
-		movie.Title = *input.Title
-	}
+	v := validator.New()
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
 
-	if input.Year != nil {
-		movie.Year = *input.Year
-	}
+	switch mediaType {
+	case "application/merge-patch+json":
+		var patch map[string]json.RawMessage
+
+		err = app.readJSON(w, r, &patch)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		data.ApplyMergePatch(v, movie, patch)
+
+	case "application/json-patch+json":
+		var operations []data.PatchOperation
+
+		err = app.readJSON(w, r, &operations)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		data.ApplyJSONPatch(v, movie, operations, genres)
+
+	case "", "application/json":
+		var input struct {
+			Title          *string            `json:"title"`
+			Year           *int32             `json:"year"`
+			Runtime        *data.Runtime      `json:"runtime"`
+			Genres         []string           `json:"genres"`
+			ExternalIDs    map[string]*string `json:"external_ids"`
+			OriginalLocale *string            `json:"original_locale"`
+		}
+
+		err = app.readJSON(w, r, &input)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
 
-	if input.Runtime != nil {
-		movie.Runtime = *input.Runtime
+		if input.Title != nil {
+			movie.Title = *input.Title
+		}
+
+		if input.Year != nil {
+			movie.Year = *input.Year
+		}
+
+		if input.Runtime != nil {
+			movie.Runtime = *input.Runtime
+		}
+
+		if input.Genres != nil {
+			movie.Genres = input.Genres
+		}
+
+		if input.OriginalLocale != nil {
+			movie.OriginalLocale = *input.OriginalLocale
+		}
+
+		// Sources given as null are removed, and those left out are kept.
+		if input.ExternalIDs != nil {
+			movie.ExternalIDs = movie.ExternalIDs.Merge(input.ExternalIDs)
+		}
+
+	default:
+		app.unsupportedMediaTypeResponse(w, r, "application/json", "application/merge-patch+json", "application/json-patch+json")
+		return
 	}
 
-	if input.Genres != nil {
-		movie.Genres = input.Genres
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
 	}
 
-	v := validator.New()
-	if data.ValidateMovie(v, movie); !v.Valid() {
+	if data.ValidateMovie(v, movie, genres); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	err = app.models.Movies.Update(movie)
+	err = app.models.Movies.Update(movie, app.contextGetUser(r).ID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
 			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateExternalID):
+			v.AddError("external_ids", "an external ID is already assigned to another movie")
+			app.failedValidationResponse(w, r, v.Errors)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	headers := make(http.Header)
+	headers.Set("ETag", movie.ETag())
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -196,11 +452,34 @@ func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = app.models.Movies.Delete(id)
+	var version int32
+
+	if r.Header.Get("If-Match") != "" || app.config.requireIfMatch {
+		movie, err := app.models.Movies.Get(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.notFoundResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		if !app.checkIfMatch(w, r, movie.ETag()) {
+			return
+		}
+
+		version = movie.Version
+	}
+
+	err = app.models.Movies.Delete(id, version)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			app.preconditionFailedResponse(w, r)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
@@ -212,3 +491,133 @@ func (app *application) deleteMovieHandler(w http.ResponseWriter, r *http.Reques
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+func (app *application) getDeletedMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+
+	lp := data.ListParams{
+		Page:         app.readInt(qs, "page", 1, v),
+		PageSize:     app.readInt(qs, "pageSize", 20, v),
+		Sort:         app.readString(qs, "sort", "-deleted_at"),
+		SortSafelist: []string{"id", "title", "deleted_at", "-id", "-title", "-deleted_at"},
+	}
+
+	if data.ValidateListParams(v, lp); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movies, metadata, err := app.models.Movies.GetDeleted(lp)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movies": movies, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) restoreMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	movie, err := app.models.Movies.Restore(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+var movieIncludes = []string{"credits", "reviews"}
+
+type movieOutput struct {
+	fieldSelection
+	locales       []string
+	runtimeFormat string
+}
+
+func (app *application) readMovieOutput(r *http.Request, v *validator.Validator) movieOutput {
+	qs := r.URL.Query()
+
+	out := movieOutput{
+		fieldSelection: app.readFieldSelection(qs, data.Movie{}, movieIncludes, v),
+		locales:        app.readLocales(r, v),
+		runtimeFormat:  app.readString(qs, "runtime_format", "mins"),
+	}
+
+	v.Check(validator.In(out.runtimeFormat, data.RuntimeFormats...), "runtime_format", "must be one of mins, iso8601, seconds or human")
+
+	return out
+}
+
+func (app *application) projectMovies(env envelope, key string, movies []*data.Movie, out movieOutput) error {
+	ids := make([]int64, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+
+	related := make(map[string][]interface{})
+
+	if out.includes("credits") {
+		credits, err := app.models.Credits.GetForMovies(ids)
+		if err != nil {
+			return err
+		}
+
+		related["credits"] = make([]interface{}, len(movies))
+		for i, id := range ids {
+			related["credits"][i] = []*data.Credit{}
+			if credits[id] != nil {
+				related["credits"][i] = credits[id]
+			}
+		}
+	}
+
+	if out.includes("reviews") {
+		reviews, err := app.models.Ratings.GetLatestReviewsForMovies(ids, 5)
+		if err != nil {
+			return err
+		}
+
+		related["reviews"] = make([]interface{}, len(movies))
+		for i, id := range ids {
+			related["reviews"][i] = []*data.Rating{}
+			if reviews[id] != nil {
+				related["reviews"][i] = reviews[id]
+			}
+		}
+	}
+
+	err := env.project(key, out.fieldSelection, related)
+	if err != nil {
+		return err
+	}
+
+	// Runtime encodes itself as "<n> mins", so any other format replaces it.
+	if out.runtimeFormat != "mins" {
+		runtimes := make([]interface{}, len(movies))
+		for i, movie := range movies {
+			runtimes[i] = movie.Runtime.Format(out.runtimeFormat)
+		}
+
+		return env.format(key, "runtime", runtimes)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+func (app *application) batchGetMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		IDs []int64 `json:"ids"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	out := app.readMovieOutput(r, v)
+
+	if data.ValidateMovieIDs(v, input.IDs); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	app.writeMoviesByIDs(w, r, input.IDs, out)
+}
+
+// writeMoviesByIDs resolves an ID that was merged away to its survivor.
+func (app *application) writeMoviesByIDs(w http.ResponseWriter, r *http.Request, ids []int64, out movieOutput) {
+	redirects, err := app.models.Movies.GetRedirects(ids)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	resolved := make([]int64, len(ids))
+	for i, id := range ids {
+		if survivorID, ok := redirects[id]; ok {
+			id = survivorID
+		}
+		resolved[i] = id
+	}
+
+	movies, err := app.models.Movies.GetByIDs(resolved)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	found := make(map[int64]bool, len(movies))
+	for _, movie := range movies {
+		found[movie.ID] = true
+	}
+
+	missing := []int64{}
+	for i, id := range ids {
+		if !found[resolved[i]] {
+			missing = append(missing, id)
+		}
+	}
+
+	_, err = app.localizeMovies(w, out.locales, movies)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{"movies": movies, "missing": missing}
+
+	err = app.projectMovies(env, "movies", movies, out)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
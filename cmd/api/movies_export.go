@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv",
+	"ndjson": "application/x-ndjson",
+	"json":   "application/json",
+}
+
+func exportFormatFromAccept(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return "csv"
+		case "application/x-ndjson", "application/ndjson":
+			return "ndjson"
+		case "application/json":
+			return "json"
+		}
+	}
+
+	return "json"
+}
+
+func (app *application) exportMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		data.MovieFilters
+		data.ListParams
+		Format string
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	var err error
+
+	input.MovieFilters, err = app.readMovieFilters(qs, v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	input.ListParams.Sort = app.readString(qs, "sort", "id")
+	input.ListParams.SortSafelist = []string{"id", "title", "year", "runtime", "average_rating", "relevance", "-id", "-title", "-year", "-runtime", "-average_rating"}
+
+	input.Format = app.readString(qs, "format", exportFormatFromAccept(r.Header.Get("Accept")))
+
+	v.Check(validator.In(input.ListParams.Sort, input.ListParams.SortSafelist...), "sort", "invalid sort value")
+	v.Check(input.ListParams.Sort != "relevance" || input.MovieFilters.Title != "", "sort", "relevance sort requires a title search")
+	v.Check(validator.In(input.Format, "csv", "ndjson", "json"), "format", "must be one of csv, ndjson or json")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(30 * time.Minute))
+
+	w.Header().Set("Content-Type", exportContentTypes[input.Format])
+	w.Header().Set("Content-Disposition", `attachment; filename="movies.`+input.Format+`"`)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+
+	buf := bufio.NewWriter(w)
+	count := 0
+
+	flush := func() error {
+		count++
+		if count%1000 != 0 {
+			return nil
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	switch input.Format {
+	case "csv":
+		cw := csv.NewWriter(buf)
+		cw.Write([]string{"id", "title", "year", "runtime", "genres"})
+
+		err = app.models.Movies.Export(input.MovieFilters, input.ListParams, func(movie *data.Movie) error {
+			err := cw.Write([]string{
+				strconv.FormatInt(movie.ID, 10),
+				movie.Title,
+				strconv.FormatInt(int64(movie.Year), 10),
+				strconv.FormatInt(int64(movie.Runtime), 10) + " mins",
+				strings.Join(movie.Genres, ","),
+			})
+			if err != nil {
+				return err
+			}
+			cw.Flush()
+			return flush()
+		})
+		cw.Flush()
+
+	case "ndjson":
+		enc := json.NewEncoder(buf)
+
+		err = app.models.Movies.Export(input.MovieFilters, input.ListParams, func(movie *data.Movie) error {
+			if err := enc.Encode(movie); err != nil {
+				return err
+			}
+			return flush()
+		})
+
+	default:
+		buf.WriteString(`{"movies":[`)
+
+		err = app.models.Movies.Export(input.MovieFilters, input.ListParams, func(movie *data.Movie) error {
+			if count > 0 {
+				buf.WriteByte(',')
+			}
+			js, err := json.Marshal(movie)
+			if err != nil {
+				return err
+			}
+			buf.Write(js)
+			return flush()
+		})
+
+		buf.WriteString("]}\n")
+	}
+
+	if err != nil {
+		app.logError(r, err)
+		return
+	}
+
+	err = buf.Flush()
+	if err != nil {
+		app.logError(r, err)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+const maxImportBytes = 100 << 20
+
+type importRowError struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+type importReport struct {
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Inserted  int              `json:"inserted"`
+	DryRun    bool             `json:"dry_run"`
+	Errors    []importRowError `json:"errors"`
+}
+
+func (app *application) importMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	dryRun := app.readBool(r.URL.Query(), "dry_run", false, v)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var readRows func(io.Reader, func(int, *data.Movie, error)) error
+
+	switch mediaType {
+	case "text/csv":
+		readRows = readMovieCSV
+	case "application/x-ndjson", "application/ndjson":
+		readRows = readMovieNDJSON
+	default:
+		app.unsupportedMediaTypeResponse(w, r, "text/csv", "application/x-ndjson")
+		return
+	}
+
+	genres, err := app.models.Genres.GetTaxonomy()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Now().Add(5 * time.Minute))
+	rc.SetWriteDeadline(time.Now().Add(6 * time.Minute))
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	report := importReport{DryRun: dryRun, Errors: []importRowError{}}
+	movies := []*data.Movie{}
+
+	err = readRows(r.Body, func(row int, movie *data.Movie, err error) {
+		report.TotalRows++
+
+		if err != nil {
+			report.Errors = append(report.Errors, importRowError{Row: row, Errors: map[string]string{"row": err.Error()}})
+			return
+		}
+
+		v := validator.New()
+
+		if data.ValidateMovie(v, movie, genres); !v.Valid() {
+			report.Errors = append(report.Errors, importRowError{Row: row, Errors: v.Errors})
+			return
+		}
+
+		movies = append(movies, movie)
+	})
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &maxBytesError):
+			app.badRequestResponse(w, r, fmt.Errorf("body must not be larger than %d bytes", maxImportBytes))
+		default:
+			app.badRequestResponse(w, r, err)
+		}
+		return
+	}
+
+	report.ValidRows = len(movies)
+
+	if !dryRun && len(movies) > 0 {
+		err = app.models.Movies.InsertMany(movies, app.contextGetUser(r).ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		report.Inserted = len(movies)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"report": report}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func readMovieCSV(body io.Reader, handle func(int, *data.Movie, error)) error {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("body must not be empty")
+		}
+		return err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		// The id column written by the export is accepted so that an export
+		// can be imported as it is, but new IDs are always assigned.
+		if !validator.In(name, "id", "title", "year", "runtime", "genres") {
+			return fmt.Errorf("csv header contains unknown column %q", name)
+		}
+		columns[name] = i
+	}
+
+	for _, name := range []string{"title", "year", "runtime", "genres"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("csv header must contain a %q column", name)
+		}
+	}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			// A malformed record is reported against its row, and reading
+			// carries on with the next one.
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			handle(row, nil, parseErr.Err)
+			continue
+		}
+
+		movie := &data.Movie{Title: record[columns["title"]]}
+
+		year, err := strconv.ParseInt(record[columns["year"]], 10, 32)
+		if err != nil {
+			handle(row, nil, errors.New("year must be an integer value"))
+			continue
+		}
+		movie.Year = int32(year)
+
+		movie.Runtime, err = data.ParseRuntime(record[columns["runtime"]])
+		if err != nil {
+			handle(row, nil, err)
+			continue
+		}
+
+		movie.Genres = []string{}
+		for _, genre := range strings.Split(record[columns["genres"]], ",") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				movie.Genres = append(movie.Genres, genre)
+			}
+		}
+
+		handle(row, movie, nil)
+	}
+}
+
+func readMovieNDJSON(body io.Reader, handle func(int, *data.Movie, error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1_048_576)
+
+	// Rows are numbered by line, blank lines included, so that a row number
+	// points straight at the line in the file.
+	for row := 1; scanner.Scan(); row++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var input struct {
+			Title   string       `json:"title"`
+			Year    int32        `json:"year"`
+			Runtime data.Runtime `json:"runtime"`
+			Genres  []string     `json:"genres"`
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+
+		err := decoder.Decode(&input)
+		if err != nil {
+			handle(row, nil, fmt.Errorf("contains invalid JSON: %w", err))
+		} else {
+			handle(row, &data.Movie{
+				Title:   input.Title,
+				Year:    input.Year,
+				Runtime: input.Runtime,
+				Genres:  input.Genres,
+			}, nil)
+		}
+	}
+
+	return scanner.Err()
+}
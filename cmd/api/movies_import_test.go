@@ -0,0 +1,140 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"greenlight.aenkas.org/internal/data"
+)
+
+type importedRow struct {
+	row   int
+	title string
+	err   bool
+}
+
+func collectRows(rows *[]importedRow) func(int, *data.Movie, error) {
+	return func(row int, movie *data.Movie, err error) {
+		r := importedRow{row: row, err: err != nil}
+		if movie != nil {
+			r.title = movie.Title
+		}
+		*rows = append(*rows, r)
+	}
+}
+
+func TestReadMovieCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []importedRow
+		wantErr bool
+	}{
+		{
+			name: "rows",
+			body: "title,year,runtime,genres\nAlien,1979,117 mins,\"sci-fi, horror\"\nHeat,1995,170,crime\n",
+			want: []importedRow{{row: 1, title: "Alien"}, {row: 2, title: "Heat"}},
+		},
+		{
+			name: "columns in any order",
+			body: "Genres,Runtime,Year,Title\ndrama,1h 30m,2001,Amelie\n",
+			want: []importedRow{{row: 1, title: "Amelie"}},
+		},
+		{
+			name: "exported id column ignored",
+			body: "id,title,year,runtime,genres\n42,Alien,1979,117 mins,sci-fi\n",
+			want: []importedRow{{row: 1, title: "Alien"}},
+		},
+		{
+			name: "bad rows reported in place",
+			body: "title,year,runtime,genres\nAlien,nineteen,117,sci-fi\nHeat,1995,long,crime\nUp,2009,96,animation\n",
+			want: []importedRow{{row: 1, err: true}, {row: 2, err: true}, {row: 3, title: "Up"}},
+		},
+		{
+			name: "malformed record reported and skipped",
+			body: "title,year,runtime,genres\nAlien,1979,117\nHeat,1995,170,crime\n",
+			want: []importedRow{{row: 1, err: true}, {row: 2, title: "Heat"}},
+		},
+		{
+			name:    "empty body",
+			body:    "",
+			wantErr: true,
+		},
+		{
+			name:    "unknown column",
+			body:    "title,year,runtime,genres,rating\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing column",
+			body:    "title,year,runtime\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []importedRow
+
+			err := readMovieCSV(strings.NewReader(tt.body), collectRows(&got))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readMovieCSV() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadMovieNDJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []importedRow
+	}{
+		{
+			name: "rows",
+			body: `{"title": "Alien", "year": 1979, "runtime": "117 mins", "genres": ["sci-fi"]}` + "\n" +
+				`{"title": "Heat", "year": 1995, "runtime": 170, "genres": ["crime"]}` + "\n",
+			want: []importedRow{{row: 1, title: "Alien"}, {row: 2, title: "Heat"}},
+		},
+		{
+			name: "no trailing newline",
+			body: `{"title": "Alien"}`,
+			want: []importedRow{{row: 1, title: "Alien"}},
+		},
+		{
+			name: "blank lines keep their numbers",
+			body: `{"title": "Alien"}` + "\n\n  \n" + `{"title": "Heat"}` + "\n",
+			want: []importedRow{{row: 1, title: "Alien"}, {row: 4, title: "Heat"}},
+		},
+		{
+			name: "bad rows reported in place",
+			body: `{"title": "Alien"` + "\n" + `{"title": "Heat", "rating": 5}` + "\n" + `{"title": "Up", "runtime": "long"}` + "\n" + `{"title": "Amelie"}`,
+			want: []importedRow{{row: 1, err: true}, {row: 2, err: true}, {row: 3, err: true}, {row: 4, title: "Amelie"}},
+		},
+		{
+			name: "empty body",
+			body: "",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []importedRow
+
+			err := readMovieNDJSON(strings.NewReader(tt.body), collectRows(&got))
+			if err != nil {
+				t.Fatalf("readMovieNDJSON() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
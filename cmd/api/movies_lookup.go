@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+func (app *application) lookupMovieHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	source := app.readString(qs, "source", "")
+	externalID := app.readString(qs, "id", "")
+
+	v := validator.New()
+
+	_, known := data.ExternalIDSources[source]
+	v.Check(source != "", "source", "must be provided")
+	v.Check(source == "" || known, "source", fmt.Sprintf("unknown source %q", source))
+
+	if known {
+		data.ValidateExternalID(v, "id", source, externalID)
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.GetByExternalID(source, externalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Content-Location", fmt.Sprintf("/v1/movies/%d", movie.ID))
+	headers.Set("ETag", movie.ETag())
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+func (app *application) mergeMovieHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		DuplicateID int64 `json:"duplicate_id"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	v.Check(input.DuplicateID > 0, "duplicate_id", "must be a positive integer")
+	v.Check(input.DuplicateID != id, "duplicate_id", "must not be the movie being merged into")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	_, err = app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	posters, err := app.models.Movies.Merge(id, input.DuplicateID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("duplicate_id", "movie does not exist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.deletePosterFiles(posters, movie.PosterURLs)
+
+	headers := make(http.Header)
+	headers.Set("ETag", movie.ETag())
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// followMovieRedirect is for reads only. Writes go through rejectMergedMovie,
+// so that they never land on a movie the client didn't name.
+func (app *application) followMovieRedirect(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := app.readIDParam(r)
+		if err != nil {
+			next(w, r)
+			return
+		}
+
+		survivorID, err := app.models.Movies.GetRedirect(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				next(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		params := httprouter.ParamsFromContext(r.Context())
+
+		resolved := make(httprouter.Params, len(params))
+		for i, param := range params {
+			if param.Key == "id" {
+				param.Value = strconv.FormatInt(survivorID, 10)
+			}
+			resolved[i] = param
+		}
+
+		ctx := context.WithValue(r.Context(), httprouter.ParamsKey, resolved)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (app *application) rejectMergedMovie(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := app.readIDParam(r)
+		if err != nil {
+			next(w, r)
+			return
+		}
+
+		survivorID, err := app.models.Movies.GetRedirect(id)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				next(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		app.mergedMovieResponse(w, r, fmt.Sprintf("/v1/movies/%d", survivorID))
+	}
+}
+
+func (app *application) redirectMergedMovie(w http.ResponseWriter, r *http.Request, id int64) {
+	survivorID, err := app.models.Movies.GetRedirect(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	location := fmt.Sprintf("/v1/movies/%d", survivorID)
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+
+	http.Redirect(w, r, location, http.StatusMovedPermanently)
+}
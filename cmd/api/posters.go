@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"net/http"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+const (
+	maxPosterBytes  = 10 << 20
+	maxPosterPixels = 40_000_000
+)
+
+var posterWidths = []int{92, 185, 342, 500}
+
+var posterSignatures = map[string][]byte{
+	"jpeg": {0xFF, 0xD8, 0xFF},
+	"png":  {0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'},
+}
+
+func (app *application) uploadPosterHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if !app.checkIfMatch(w, r, movie.ETag()) {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data", "image/jpeg", "image/png":
+	default:
+		app.unsupportedMediaTypeResponse(w, r, "multipart/form-data", "image/jpeg", "image/png")
+		return
+	}
+
+	b, err := app.readUpload(w, r, "poster", maxPosterBytes)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	format := posterFormat(b)
+	if v.Check(format != "", "poster", "must be a JPEG or PNG image"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		v.AddError("poster", "must be a valid image")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if v.Check(config.Width*config.Height <= maxPosterPixels, "poster", "must not be larger than 40 megapixels"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		v.AddError("poster", "must be a valid image")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Unique keys mean no file in use is overwritten and no cache goes stale.
+	token := make([]byte, 8)
+
+	_, err = rand.Read(token)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	key := func(size string) string {
+		return fmt.Sprintf("posters/%d/%d-%x/%s", movie.ID, movie.Version, token, size)
+	}
+
+	urls := data.PosterURLs{}
+
+	err = app.storage.Put(key("original"), bytes.NewReader(b))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	urls["original"] = app.storage.URL(key("original"))
+
+	for _, width := range posterWidths {
+		if width >= img.Bounds().Dx() {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		thumbnail := resizeImage(img, width)
+
+		switch format {
+		case "png":
+			err = png.Encode(&buf, thumbnail)
+		default:
+			err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		size := fmt.Sprintf("w%d", width)
+
+		err = app.storage.Put(key(size), &buf)
+		if err != nil {
+			app.deletePosterFiles(urls, movie.PosterURLs)
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		urls[size] = app.storage.URL(key(size))
+	}
+
+	previous := movie.PosterURLs
+	movie.PosterURLs = urls
+
+	err = app.models.Movies.SetPoster(movie.ID, previous, urls)
+	if err != nil {
+		app.deletePosterFiles(urls, previous)
+
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.deletePosterFiles(previous, urls)
+
+	headers := make(http.Header)
+	headers.Set("ETag", movie.ETag())
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"movie": movie}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deletePosterFiles only logs failures, as nothing points at the files any
+// more.
+func (app *application) deletePosterFiles(urls, keep data.PosterURLs) {
+	kept := make(map[string]bool, len(keep))
+	for _, url := range keep {
+		kept[url] = true
+	}
+
+	for _, url := range urls {
+		if kept[url] {
+			continue
+		}
+
+		key, ok := app.storage.Key(url)
+		if !ok {
+			continue
+		}
+
+		err := app.storage.Delete(key)
+		if err != nil {
+			app.logger.PrintError(err, map[string]string{"key": key})
+		}
+	}
+}
+
+func posterFormat(b []byte) string {
+	for format, signature := range posterSignatures {
+		if bytes.HasPrefix(b, signature) {
+			return format
+		}
+	}
+
+	return ""
+}
+
+func resizeImage(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+
+			var r, g, b, a, n uint64
+
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+
+	return dst
+}
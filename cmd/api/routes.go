@@ -19,16 +19,76 @@ func (app *application) routes() http.Handler {
 	router.HandlerFunc(http.MethodPut, "/v1/users/activate", app.activateUserHandler)
 	router.HandlerFunc(http.MethodPut, "/v1/users/password", app.requireActivatedUser(app.updateUserPasswordHandler))
 
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/watchlist", app.requireActivatedUser(app.getWatchlistHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/users/me/watchlist", app.requireActivatedUser(app.addWatchlistItemHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/users/me/watchlist", app.requireActivatedUser(app.reorderWatchlistHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/users/me/watchlist/:id", app.requireActivatedUser(app.removeWatchlistItemHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/searches", app.requireActivatedUser(app.getSavedSearchesHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/users/me/searches", app.requireActivatedUser(app.createSavedSearchHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/searches/:id", app.requireActivatedUser(app.getSavedSearchHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/users/me/searches/:id", app.requireActivatedUser(app.updateSavedSearchHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/users/me/searches/:id", app.requireActivatedUser(app.deleteSavedSearchHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/history", app.requireActivatedUser(app.getWatchHistoryHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/users/me/history", app.requireActivatedUser(app.logWatchHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/users/me/history/summary", app.requireActivatedUser(app.getWatchSummaryHandler))
+
 	router.HandlerFunc(http.MethodPost, "/v1/tokens/authentication", app.createAuthenticationTokenHandler)
 	router.HandlerFunc(http.MethodPost, "/v1/tokens/password-reset", app.requireActivatedUser(app.createPasswordResetTokenHandler))
 
+	router.HandlerFunc(http.MethodGet, "/v1/genres", app.requirePermission("movies:read", app.getGenresHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/stats/movies", app.requirePermission("movies:read", app.getMovieStatsHandler))
+
 	router.HandlerFunc(http.MethodGet, "/v1/movies", app.requirePermission("movies:read", app.getMoviesHandler))
-	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", app.requirePermission("movies:read", app.getMovieHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id", app.routeStaticID(app.requirePermission("movies:read", app.getMovieHandler), map[string]http.HandlerFunc{
+		"export": app.requirePermission("movies:read", app.exportMoviesHandler),
+		"lookup": app.requirePermission("movies:read", app.lookupMovieHandler),
+		"trash":  app.requirePermission("movies:write", app.getDeletedMoviesHandler),
+	}))
 	router.HandlerFunc(http.MethodPost, "/v1/movies", app.requirePermission("movies:write", app.createMovieHandler))
-	router.HandlerFunc(http.MethodPatch, "/v1/movies/:id", app.requirePermission("movies:write", app.updateMovieHandler))
-	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id", app.requirePermission("movies:write", app.deleteMovieHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id", app.routeStaticID(app.notFoundResponse, map[string]http.HandlerFunc{
+		"batch":  app.requirePermission("movies:read", app.batchGetMoviesHandler),
+		"import": app.requirePermission("movies:write", app.importMoviesHandler),
+	}))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/restore", app.requirePermission("movies:write", app.rejectMergedMovie(app.restoreMovieHandler)))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/merge", app.requirePermission("movies:write", app.rejectMergedMovie(app.mergeMovieHandler)))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/revisions", app.requirePermission("movies:read", app.followMovieRedirect(app.getMovieRevisionsHandler)))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/revisions/:version/revert", app.requirePermission("movies:write", app.rejectMergedMovie(app.revertMovieHandler)))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/translations", app.requirePermission("movies:read", app.followMovieRedirect(app.getMovieTranslationsHandler)))
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/translations/:locale", app.requirePermission("movies:write", app.rejectMergedMovie(app.putMovieTranslationHandler)))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id/translations/:locale", app.requirePermission("movies:write", app.rejectMergedMovie(app.deleteMovieTranslationHandler)))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/similar", app.requirePermission("movies:read", app.followMovieRedirect(app.getSimilarMoviesHandler)))
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/poster", app.requirePermission("movies:write", app.rejectMergedMovie(app.uploadPosterHandler)))
+	router.HandlerFunc(http.MethodPut, "/v1/movies/:id/rating", app.requireActivatedUser(app.rejectMergedMovie(app.rateMovieHandler)))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/reviews", app.requirePermission("movies:read", app.followMovieRedirect(app.getMovieReviewsHandler)))
+	router.HandlerFunc(http.MethodGet, "/v1/movies/:id/credits", app.requirePermission("movies:read", app.followMovieRedirect(app.getMovieCreditsHandler)))
+	router.HandlerFunc(http.MethodPost, "/v1/movies/:id/credits", app.requirePermission("people:write", app.rejectMergedMovie(app.createCreditHandler)))
+	router.HandlerFunc(http.MethodDelete, "/v1/credits/:id", app.requirePermission("people:write", app.deleteCreditHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/people", app.requirePermission("people:read", app.getPeopleHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/people/:id", app.requirePermission("people:read", app.getPersonHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/people/:id/filmography", app.requirePermission("people:read", app.getFilmographyHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/people", app.requirePermission("people:write", app.createPersonHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/people/:id", app.requirePermission("people:write", app.updatePersonHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/people/:id", app.requirePermission("people:write", app.deletePersonHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/movies/:id", app.requirePermission("movies:write", app.rejectMergedMovie(app.updateMovieHandler)))
+	router.HandlerFunc(http.MethodDelete, "/v1/movies/:id", app.requirePermission("movies:write", app.rejectMergedMovie(app.deleteMovieHandler)))
 
 	router.Handler(http.MethodGet, "/v1/metrics", expvar.Handler())
 
+	router.Handler(http.MethodGet, "/uploads/*filepath", http.StripPrefix("/uploads", http.FileServer(uploadsFS{root: http.Dir(app.config.storage.dir)})))
+
 	return app.metrics(app.recoverPanic(app.enableCORS(app.rateLimit(app.authenticate(router)))))
 }
+
+func (app *application) routeStaticID(byID http.HandlerFunc, static map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := httprouter.ParamsFromContext(r.Context())
+
+		if next, ok := static[params.ByName("id")]; ok {
+			next(w, r)
+			return
+		}
+
+		byID(w, r)
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var savedSearchTemplates = map[string]string{
+	"immediate": "saved_search_match.html",
+	"daily":     "saved_search_digest.html",
+}
+
+func (app *application) validateSavedSearchQuery(v *validator.Validator, s *data.SavedSearch) error {
+	qs, err := url.ParseQuery(s.Query)
+	if err != nil {
+		v.AddError("query", "must be a valid query string")
+		return nil
+	}
+
+	fv := validator.New()
+
+	_, err = app.readMovieFilters(qs, fv)
+	if err != nil {
+		return err
+	}
+
+	for key, message := range fv.Errors {
+		v.AddError("query."+key, message)
+	}
+
+	s.Query = qs.Encode()
+
+	return nil
+}
+
+func (app *application) getSavedSearchesHandler(w http.ResponseWriter, r *http.Request) {
+	searches, err := app.models.SavedSearches.GetForUser(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"searches": searches}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) createSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name      string `json:"name"`
+		Query     string `json:"query"`
+		Frequency string `json:"frequency"`
+	}
+
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	search := &data.SavedSearch{
+		UserID:    app.contextGetUser(r).ID,
+		Name:      input.Name,
+		Query:     input.Query,
+		Frequency: input.Frequency,
+	}
+
+	if search.Frequency == "" {
+		search.Frequency = "daily"
+	}
+
+	v := validator.New()
+
+	err = app.validateSavedSearchQuery(v, search)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if data.ValidateSavedSearch(v, search); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.SavedSearches.Insert(search)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateSavedSearch):
+			v.AddError("name", "a saved search with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"search": search}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) getSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	search, err := app.models.SavedSearches.Get(id, app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"search": search}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) updateSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	search, err := app.models.SavedSearches.Get(id, app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Name      *string `json:"name"`
+		Query     *string `json:"query"`
+		Frequency *string `json:"frequency"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Name != nil {
+		search.Name = *input.Name
+	}
+
+	if input.Query != nil {
+		search.Query = *input.Query
+	}
+
+	if input.Frequency != nil {
+		search.Frequency = *input.Frequency
+	}
+
+	v := validator.New()
+
+	err = app.validateSavedSearchQuery(v, search)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if data.ValidateSavedSearch(v, search); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.SavedSearches.Update(search)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateSavedSearch):
+			v.AddError("name", "a saved search with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"search": search}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteSavedSearchHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.SavedSearches.Delete(id, app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "saved search successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) matchSavedSearches() error {
+	for {
+		ids, err := app.models.SavedSearches.GetQueued(1000)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			break
+		}
+
+		err = app.matchSavedSearchMovies(ids)
+		if err != nil {
+			return err
+		}
+
+		err = app.models.SavedSearches.Dequeue(ids)
+		if err != nil {
+			return err
+		}
+	}
+
+	return app.sendSavedSearchAlerts("immediate")
+}
+
+func (app *application) matchSavedSearchMovies(movieIDs []int64) error {
+	searches, err := app.models.SavedSearches.GetAll()
+	if err != nil {
+		return err
+	}
+
+	genres, err := app.models.Genres.GetTaxonomy()
+	if err != nil {
+		return err
+	}
+
+	// Queries are stored in canonical form, so searches saved with the same
+	// filters share a query string.
+	byQuery := make(map[string][]int64)
+	for _, search := range searches {
+		byQuery[search.Query] = append(byQuery[search.Query], search.ID)
+	}
+
+	for query, searchIDs := range byQuery {
+		qs, err := url.ParseQuery(query)
+		if err != nil {
+			return err
+		}
+
+		// Filters that no longer validate, say a search language since
+		// dropped, can't match anything.
+		v := validator.New()
+
+		f := app.parseMovieFilters(qs, genres, v)
+		if !v.Valid() {
+			continue
+		}
+
+		matched, err := app.models.Movies.MatchFilters(movieIDs, f)
+		if err != nil {
+			return err
+		}
+
+		if len(matched) > 0 {
+			err = app.models.SavedSearches.AddMatches(searchIDs, matched)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sendSavedSearchAlerts only marks matches as notified once their email has
+// been sent, so a failed send is retried next time round.
+func (app *application) sendSavedSearchAlerts(frequency string) error {
+	app.alertsMu.Lock()
+	defer app.alertsMu.Unlock()
+
+	alerts, err := app.models.SavedSearches.GetPendingAlerts(frequency)
+	if err != nil {
+		return err
+	}
+
+	type search struct {
+		Name   string
+		Movies []*data.SavedSearchAlert
+	}
+
+	for start := 0; start < len(alerts); {
+		end := start
+		for end < len(alerts) && alerts[end].UserEmail == alerts[start].UserEmail {
+			end++
+		}
+
+		userAlerts := alerts[start:end]
+		start = end
+
+		var searches []*search
+
+		for _, a := range userAlerts {
+			if len(searches) == 0 || searches[len(searches)-1].Name != a.SearchName {
+				searches = append(searches, &search{Name: a.SearchName})
+			}
+
+			last := searches[len(searches)-1]
+			last.Movies = append(last.Movies, a)
+		}
+
+		emailData := map[string]interface{}{
+			"userName": userAlerts[0].UserName,
+			"searches": searches,
+		}
+
+		err = app.mailer.Send(userAlerts[0].UserEmail, savedSearchTemplates[frequency], emailData)
+		if err != nil {
+			app.logger.PrintError(err, nil)
+			continue
+		}
+
+		err = app.models.SavedSearches.MarkNotified(userAlerts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -43,6 +43,9 @@ func (app *application) serve() error {
 			"addr": srv.Addr,
 		})
 
+		// Stop the job tickers first, so nothing adds to the wait group
+		// while we wait on it.
+		app.stopJobs()
 		app.wg.Wait()
 		shutdownError <- err
 	}()
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+const maxSimilarMovies = 20
+
+var similarityStrategies = []string{"features", "blended"}
+
+func (app *application) similarityScorer(coRatings []data.CoRating) data.SimilarityScorer {
+	switch app.config.similarity.strategy {
+	case "features":
+		return data.NewFeatureScorer()
+	default:
+		return data.BlendedScorer{
+			{Scorer: data.NewFeatureScorer(), Weight: 0.7},
+			{Scorer: data.NewCoRatingScorer(coRatings), Weight: 0.3},
+		}
+	}
+}
+
+func (app *application) getSimilarMoviesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+
+	limit := app.readInt(r.URL.Query(), "limit", 10, v)
+	out := app.readMovieOutput(r, v)
+
+	v.Check(limit > 0, "limit", "must be greater than zero")
+	v.Check(limit <= maxSimilarMovies, "limit", "must be a maximum of 20")
+
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	movie, err := app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	similar, err := app.models.MovieNeighbours.GetForMovie(movie.ID, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Movies added since the nightly rebuild have no neighbours yet.
+	if len(similar) == 0 {
+		candidates, err := app.models.MovieNeighbours.GetCandidates(movie, 500)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		similar = data.RankSimilar(movie, candidates, app.similarityScorer(nil), limit)
+	}
+
+	movies := make([]*data.Movie, len(similar))
+	for i := range similar {
+		movies[i] = &similar[i].Movie
+	}
+
+	_, err = app.localizeMovies(w, out.locales, movies)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// The score is part of every similar movie, whatever fields were asked for.
+	if len(out.fields) > 0 {
+		out.fields = append(out.fields, "similarity")
+	}
+
+	env := envelope{"movies": similar}
+
+	err = app.projectMovies(env, "movies", movies, out)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"greenlight.aenkas.org/internal/data"
+)
+
+const statsNewestMovies = 10
+
+type statsCache struct {
+	mu      sync.Mutex
+	stats   data.MovieStats
+	expires time.Time
+}
+
+// get holds the lock while computing, so that concurrent requests wait for one
+// query rather than each running their own.
+func (c *statsCache) get(ttl time.Duration, compute func() (data.MovieStats, error)) (data.MovieStats, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.expires) {
+		return c.stats, c.expires, nil
+	}
+
+	stats, err := compute()
+	if err != nil {
+		return data.MovieStats{}, time.Time{}, err
+	}
+
+	c.stats = stats
+	c.expires = time.Now().Add(ttl)
+
+	return c.stats, c.expires, nil
+}
+
+func (app *application) getMovieStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, expires, err := app.statsCache.get(app.config.stats.ttl, func() (data.MovieStats, error) {
+		return app.models.Movies.GetStats(statsNewestMovies)
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(time.Until(expires).Seconds())))
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"stats": stats}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
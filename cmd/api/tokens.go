@@ -23,6 +23,8 @@ func (app *application) createAuthenticationTokenHandler(w http.ResponseWriter,
 
 	v := validator.New()
 
+	fs := app.readFieldSelection(r.URL.Query(), data.Token{}, nil, v)
+
 	data.ValidateEmail(v, input.Email)
 	data.ValidatePassword(v, input.Password)
 
@@ -59,7 +61,15 @@ func (app *application) createAuthenticationTokenHandler(w http.ResponseWriter,
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusCreated, envelope{"token": token}, nil)
+	env := envelope{"token": token}
+
+	err = env.project("token", fs, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, env, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
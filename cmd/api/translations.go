@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"greenlight.aenkas.org/internal/data"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+// readLocales lets a lang query string parameter override Accept-Language.
+func (app *application) readLocales(r *http.Request, v *validator.Validator) []string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		locale := data.NormalizeLocale(lang)
+		data.ValidateLocale(v, "lang", locale)
+		return []string{locale}
+	}
+
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var accepted []weighted
+
+	for _, entry := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		locale := data.NormalizeLocale(tag)
+
+		if !validator.Matches(locale, data.LocaleRX) {
+			continue
+		}
+
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > 0 {
+			accepted = append(accepted, weighted{locale: locale, q: q})
+		}
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	locales := make([]string, len(accepted))
+	for i, a := range accepted {
+		locales[i] = a.locale
+	}
+
+	return locales
+}
+
+func (app *application) localizeMovies(w http.ResponseWriter, locales []string, movies []*data.Movie) (bool, error) {
+	w.Header().Add("Vary", "Accept-Language")
+
+	if len(locales) == 0 || len(movies) == 0 {
+		return false, nil
+	}
+
+	ids := make([]int64, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+
+	languages := make([]string, len(locales))
+	for i, locale := range locales {
+		languages[i] = data.LocaleLanguage(locale)
+	}
+
+	translations, err := app.models.MovieTranslations.GetForMovies(ids, languages)
+	if err != nil {
+		return false, err
+	}
+
+	used := []string{}
+
+	for _, movie := range movies {
+		t := data.BestTranslation(translations[movie.ID], movie.OriginalLocale, locales)
+		if t == nil {
+			continue
+		}
+
+		movie.OriginalTitle = movie.Title
+		movie.Title = t.Title
+
+		if !validator.In(t.Locale, used...) {
+			used = append(used, t.Locale)
+		}
+	}
+
+	if len(used) > 0 {
+		w.Header().Set("Content-Language", strings.Join(used, ", "))
+	}
+
+	return len(used) > 0, nil
+}
+
+func (app *application) readLocaleParam(r *http.Request) string {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	return data.NormalizeLocale(params.ByName("locale"))
+}
+
+func (app *application) getMovieTranslationsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	translations, err := app.models.MovieTranslations.GetForMovie(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"translations": translations}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) putMovieTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.models.Movies.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var input struct {
+		Title string `json:"title"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	translation := &data.MovieTranslation{
+		MovieID: id,
+		Locale:  app.readLocaleParam(r),
+		Title:   input.Title,
+	}
+
+	v := validator.New()
+
+	if data.ValidateMovieTranslation(v, translation); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.MovieTranslations.Upsert(translation)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"translation": translation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+func (app *application) deleteMovieTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.MovieTranslations.Delete(id, app.readLocaleParam(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "translation successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
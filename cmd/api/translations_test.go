@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"greenlight.aenkas.org/internal/validator"
+)
+
+func TestReadLocales(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		want           []string
+		wantErr        bool
+	}{
+		{name: "none", url: "/v1/movies", want: []string{}},
+		{name: "single", url: "/v1/movies", acceptLanguage: "de", want: []string{"de"}},
+		{name: "in order", url: "/v1/movies", acceptLanguage: "de, fr", want: []string{"de", "fr"}},
+		{name: "by quality", url: "/v1/movies", acceptLanguage: "fr;q=0.5, de;q=0.9, en", want: []string{"en", "de", "fr"}},
+		{name: "ties keep their order", url: "/v1/movies", acceptLanguage: "fr;q=0.8, de;q=0.8", want: []string{"fr", "de"}},
+		{name: "normalised", url: "/v1/movies", acceptLanguage: "PT_br", want: []string{"pt-BR"}},
+		{name: "zero quality dropped", url: "/v1/movies", acceptLanguage: "de, fr;q=0", want: []string{"de"}},
+		{name: "wildcard dropped", url: "/v1/movies", acceptLanguage: "*, de;q=0.5", want: []string{"de"}},
+		{name: "bad quality dropped", url: "/v1/movies", acceptLanguage: "de;q=x, fr", want: []string{"fr"}},
+		{name: "lang overrides header", url: "/v1/movies?lang=pt_br", acceptLanguage: "de", want: []string{"pt-BR"}},
+		{name: "invalid lang", url: "/v1/movies?lang=german", wantErr: true},
+	}
+
+	app := &application{}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			v := validator.New()
+			got := app.readLocales(r, v)
+
+			if tt.wantErr {
+				if _, ok := v.Errors["lang"]; !ok {
+					t.Fatalf("errors = %v, want one for lang", v.Errors)
+				}
+				return
+			}
+
+			if !v.Valid() {
+				t.Fatalf("unexpected errors: %v", v.Errors)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readLocales() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
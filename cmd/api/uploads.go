@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// uploadsFS hides directories, so there are no listings, and dot files, which
+// include uploads still being written.
+type uploadsFS struct {
+	root http.FileSystem
+}
+
+func (u uploadsFS) Open(name string) (http.File, error) {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return nil, fs.ErrNotExist
+		}
+	}
+
+	f, err := u.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		f.Close()
+		return nil, fs.ErrNotExist
+	}
+
+	return f, nil
+}
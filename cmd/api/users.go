@@ -36,6 +36,8 @@ func (app *application) signupUserHandler(w http.ResponseWriter, r *http.Request
 
 	v := validator.New()
 
+	fs := app.readFieldSelection(r.URL.Query(), data.User{}, nil, v)
+
 	if data.ValidateUser(v, user); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -53,7 +55,7 @@ func (app *application) signupUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = app.models.Permissions.GrantForUser(user.ID, "movies:read")
+	err = app.models.Permissions.GrantForUser(user.ID, "movies:read", "people:read")
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -76,7 +78,15 @@ func (app *application) signupUserHandler(w http.ResponseWriter, r *http.Request
 		}
 	})
 
-	err = app.writeJSON(w, http.StatusCreated, envelope{"user": user}, nil)
+	env := envelope{"user": user}
+
+	err = env.project("user", fs, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, env, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -95,6 +105,8 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 
 	v := validator.New()
 
+	fs := app.readFieldSelection(r.URL.Query(), data.User{}, nil, v)
+
 	if data.ValidateTokenPlaintext(v, input.TokenPlainText); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -130,7 +142,15 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil)
+	env := envelope{"user": user}
+
+	err = env.project("user", fs, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, env, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
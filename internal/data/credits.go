@@ -0,0 +1,214 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var CreditRoles = []string{"director", "actor", "writer"}
+
+type Credit struct {
+	ID         int64  `json:"id"`
+	MovieID    int64  `json:"movie_id"`
+	MovieTitle string `json:"movie_title,omitempty"`
+	MovieYear  int32  `json:"movie_year,omitempty"`
+	PersonID   int64  `json:"person_id"`
+	PersonName string `json:"person_name,omitempty"`
+	Role       string `json:"role"`
+	Character  string `json:"character,omitempty"`
+}
+
+func ValidateCredit(v *validator.Validator, credit *Credit) {
+	v.Check(credit.PersonID > 0, "person_id", "must be provided")
+	v.Check(validator.In(credit.Role, CreditRoles...), "role", "must be one of director, actor or writer")
+	v.Check(credit.Character == "" || credit.Role == "actor", "character", "must only be provided for actors")
+	v.Check(len(credit.Character) <= 500, "character", "must not be more than 500 bytes long")
+}
+
+type CreditModel struct {
+	DB *sql.DB
+}
+
+func (m CreditModel) Insert(credit *Credit) error {
+	query := `
+	INSERT INTO credits (movie_id, person_id, role, character)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id`
+
+	args := []interface{}{credit.MovieID, credit.PersonID, credit.Role, credit.Character}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&credit.ID)
+}
+
+func (m CreditModel) GetForMovie(movieID int64) ([]*Credit, error) {
+	query := `
+	SELECT c.id, c.movie_id, c.person_id, p.name, c.role, c.character
+	FROM credits c
+	INNER JOIN people p
+	ON p.id = c.person_id
+	WHERE c.movie_id = $1
+	ORDER BY c.role ASC, c.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []*Credit{}
+
+	for rows.Next() {
+		var credit Credit
+
+		err := rows.Scan(&credit.ID, &credit.MovieID, &credit.PersonID, &credit.PersonName, &credit.Role, &credit.Character)
+		if err != nil {
+			return nil, err
+		}
+
+		credits = append(credits, &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+func (m CreditModel) GetForMovies(movieIDs []int64) (map[int64][]*Credit, error) {
+	query := `
+	SELECT c.id, c.movie_id, c.person_id, p.name, c.role, c.character
+	FROM credits c
+	INNER JOIN people p
+	ON p.id = c.person_id
+	WHERE c.movie_id = ANY($1)
+	ORDER BY c.movie_id ASC, c.role ASC, c.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(movieIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := make(map[int64][]*Credit)
+
+	for rows.Next() {
+		var credit Credit
+
+		err := rows.Scan(&credit.ID, &credit.MovieID, &credit.PersonID, &credit.PersonName, &credit.Role, &credit.Character)
+		if err != nil {
+			return nil, err
+		}
+
+		credits[credit.MovieID] = append(credits[credit.MovieID], &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+func (m CreditModel) GetFilmography(personID int64) ([]*Credit, error) {
+	query := `
+	SELECT c.id, c.movie_id, m.title, m.year, c.person_id, c.role, c.character
+	FROM credits c
+	INNER JOIN movies m
+	ON m.id = c.movie_id
+	WHERE c.person_id = $1
+	  AND m.deleted_at IS NULL
+	ORDER BY m.year DESC, m.id ASC, c.role ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []*Credit{}
+
+	for rows.Next() {
+		var credit Credit
+
+		err := rows.Scan(&credit.ID, &credit.MovieID, &credit.MovieTitle, &credit.MovieYear, &credit.PersonID, &credit.Role, &credit.Character)
+		if err != nil {
+			return nil, err
+		}
+
+		credits = append(credits, &credit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
+
+func (m CreditModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+	DELETE FROM credits
+	WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type MockCreditModel struct{}
+
+func (m MockCreditModel) Insert(credit *Credit) error {
+	return nil
+}
+
+func (m MockCreditModel) GetForMovie(movieID int64) ([]*Credit, error) {
+	return nil, nil
+}
+
+func (m MockCreditModel) GetForMovies(movieIDs []int64) (map[int64][]*Credit, error) {
+	return nil, nil
+}
+
+func (m MockCreditModel) GetFilmography(personID int64) ([]*Credit, error) {
+	return nil, nil
+}
+
+func (m MockCreditModel) Delete(id int64) error {
+	return nil
+}
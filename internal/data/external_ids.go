@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var ErrDuplicateExternalID = errors.New("duplicate external id")
+
+var ExternalIDSources = map[string]*regexp.Regexp{
+	"imdb":     regexp.MustCompile(`^tt\d{7,}$`),
+	"tmdb":     regexp.MustCompile(`^\d+$`),
+	"wikidata": regexp.MustCompile(`^Q\d+$`),
+}
+
+type ExternalIDs map[string]string
+
+func (e *ExternalIDs) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into ExternalIDs", src)
+	}
+
+	ids := ExternalIDs{}
+
+	err := json.Unmarshal(b, &ids)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		ids = nil
+	}
+
+	*e = ids
+
+	return nil
+}
+
+// Merge never returns nil, so that saving the result replaces the movie's
+// stored IDs.
+func (e ExternalIDs) Merge(changes map[string]*string) ExternalIDs {
+	merged := ExternalIDs{}
+
+	for source, externalID := range e {
+		merged[source] = externalID
+	}
+
+	for source, externalID := range changes {
+		if externalID == nil {
+			delete(merged, source)
+			continue
+		}
+		merged[source] = *externalID
+	}
+
+	return merged
+}
+
+func (e ExternalIDs) equal(other ExternalIDs) bool {
+	if len(e) != len(other) {
+		return false
+	}
+
+	for source, externalID := range e {
+		if value, ok := other[source]; !ok || value != externalID {
+			return false
+		}
+	}
+
+	return true
+}
+
+func externalIDSourceNames() []string {
+	names := make([]string, 0, len(ExternalIDSources))
+	for name := range ExternalIDSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ValidateExternalID(v *validator.Validator, key, source, externalID string) {
+	rx, ok := ExternalIDSources[source]
+	if !ok {
+		v.AddError(key, fmt.Sprintf("unknown source %q, must be one of %s", source, strings.Join(externalIDSourceNames(), ", ")))
+		return
+	}
+
+	v.Check(externalID != "", key, "must be provided")
+	v.Check(externalID == "" || validator.Matches(externalID, rx), key, fmt.Sprintf("is not a valid %s identifier", source))
+}
+
+func ValidateExternalIDs(v *validator.Validator, ids ExternalIDs) {
+	for source, externalID := range ids {
+		ValidateExternalID(v, "external_ids."+source, source, externalID)
+	}
+}
+
+func externalIDsColumn(table string) string {
+	return `(SELECT COALESCE(jsonb_object_agg(source, external_id), '{}') FROM movie_external_ids WHERE movie_id = ` + table + `.id)`
+}
+
+// replaceExternalIDs leaves the movie's IDs as they are when ids is nil.
+func replaceExternalIDs(ctx context.Context, tx *sql.Tx, movieID int64, ids ExternalIDs) error {
+	if ids == nil {
+		return nil
+	}
+
+	sources := make([]string, 0, len(ids))
+	externalIDs := make([]string, 0, len(ids))
+
+	for source, externalID := range ids {
+		sources = append(sources, source)
+		externalIDs = append(externalIDs, externalID)
+	}
+
+	query := `
+	DELETE FROM movie_external_ids
+	WHERE movie_id = $1 AND NOT source = ANY($2)`
+
+	_, err := tx.ExecContext(ctx, query, movieID, pq.Array(sources))
+	if err != nil {
+		return err
+	}
+
+	query = `
+	INSERT INTO movie_external_ids (movie_id, source, external_id)
+	SELECT $1, s, e FROM unnest($2::text[], $3::text[]) AS u(s, e)
+	ON CONFLICT (movie_id, source)
+	DO UPDATE SET external_id = EXCLUDED.external_id`
+
+	_, err = tx.ExecContext(ctx, query, movieID, pq.Array(sources), pq.Array(externalIDs))
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "movie_external_ids_source_external_id_key"`:
+			return ErrDuplicateExternalID
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m MovieModel) GetByExternalID(source, externalID string) (*Movie, error) {
+	query := `
+	SELECT movie_id
+	FROM movie_external_ids
+	WHERE source = $1 AND external_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var id int64
+
+	err := m.DB.QueryRowContext(ctx, query, source, externalID).Scan(&id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return m.Get(id)
+}
+
+func (m MockMovieModel) GetByExternalID(source, externalID string) (*Movie, error) {
+	return nil, nil
+}
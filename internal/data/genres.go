@@ -0,0 +1,172 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+type Genre struct {
+	ID         int64    `json:"id"`
+	Slug       string   `json:"slug"`
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases"`
+	MovieCount int      `json:"movie_count"`
+}
+
+// GenreTaxonomy is keyed by the genreKey of every slug and alias.
+type GenreTaxonomy map[string]string
+
+func (t GenreTaxonomy) Canonical(genre string) (string, bool) {
+	slug, ok := t[genreKey(genre)]
+	return slug, ok
+}
+
+func (t GenreTaxonomy) sameGenres(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+
+		// A genre outside the taxonomy only matches itself.
+		slugA, okA := t.Canonical(a[i])
+		slugB, okB := t.Canonical(b[i])
+		if !okA || !okB || slugA != slugB {
+			return false
+		}
+	}
+
+	return true
+}
+
+// genreKey turns "Sci Fi" and "sci_fi" alike into "sci-fi". The 000015
+// migration applies the same rule in SQL, so the two must change together.
+func genreKey(genre string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range strings.ToLower(genre) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+
+	return b.String()
+}
+
+func canonicalizeGenres(v *validator.Validator, key string, genres []string, taxonomy GenreTaxonomy) {
+	for i, genre := range genres {
+		slug, ok := taxonomy.Canonical(genre)
+		if !ok {
+			v.AddError(key, fmt.Sprintf("unknown genre %q", genre))
+			continue
+		}
+		genres[i] = slug
+	}
+}
+
+type GenreModel struct {
+	DB *sql.DB
+}
+
+func (m GenreModel) GetAll() ([]*Genre, error) {
+	query := `
+	SELECT g.id, g.slug, g.name, g.aliases, count(m.id)
+	FROM genres g
+	LEFT JOIN movies m
+	ON g.slug = ANY(m.genres) AND m.deleted_at IS NULL
+	GROUP BY g.id
+	ORDER BY g.name ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []*Genre{}
+
+	for rows.Next() {
+		var genre Genre
+
+		err := rows.Scan(&genre.ID, &genre.Slug, &genre.Name, pq.Array(&genre.Aliases), &genre.MovieCount)
+		if err != nil {
+			return nil, err
+		}
+
+		genres = append(genres, &genre)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return genres, nil
+}
+
+func (m GenreModel) GetTaxonomy() (GenreTaxonomy, error) {
+	query := `
+	SELECT slug, aliases
+	FROM genres`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxonomy := GenreTaxonomy{}
+
+	for rows.Next() {
+		var slug string
+		var aliases []string
+
+		err := rows.Scan(&slug, pq.Array(&aliases))
+		if err != nil {
+			return nil, err
+		}
+
+		taxonomy[slug] = slug
+		for _, alias := range aliases {
+			taxonomy[genreKey(alias)] = slug
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return taxonomy, nil
+}
+
+type MockGenreModel struct{}
+
+func (m MockGenreModel) GetAll() ([]*Genre, error) {
+	return nil, nil
+}
+
+func (m MockGenreModel) GetTaxonomy() (GenreTaxonomy, error) {
+	return GenreTaxonomy{}, nil
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	"greenlight.aenkas.org/internal/validator"
+)
+
+func TestGenreKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "sci-fi", want: "sci-fi"},
+		{input: "Sci Fi", want: "sci-fi"},
+		{input: "sci_fi", want: "sci-fi"},
+		{input: "SCI--FI", want: "sci-fi"},
+		{input: "  Sci  Fi  ", want: "sci-fi"},
+		{input: "Science Fiction!", want: "science-fiction"},
+		{input: "film-noir", want: "film-noir"},
+		{input: "Film Noir", want: "film-noir"},
+		{input: "Café", want: "café"},
+		{input: "1970s", want: "1970s"},
+		{input: "---", want: ""},
+		{input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := genreKey(tt.input); got != tt.want {
+				t.Errorf("genreKey(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenreTaxonomyCanonical(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{input: "sci-fi", want: "sci-fi", wantOK: true},
+		{input: "Sci Fi", want: "sci-fi", wantOK: true},
+		{input: "science fiction", want: "sci-fi", wantOK: true},
+		{input: "Science_Fiction", want: "sci-fi", wantOK: true},
+		{input: "HORROR", want: "horror", wantOK: true},
+		{input: "western", wantOK: false},
+		{input: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := testTaxonomy.Canonical(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Canonical(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeGenres(t *testing.T) {
+	tests := []struct {
+		name    string
+		genres  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "slugs", genres: []string{"sci-fi", "drama"}, want: []string{"sci-fi", "drama"}},
+		{name: "aliases", genres: []string{"Science Fiction", "Horror"}, want: []string{"sci-fi", "horror"}},
+		{name: "empty", genres: []string{}, want: []string{}},
+		{name: "unknown", genres: []string{"drama", "western"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+
+			canonicalizeGenres(v, "genres", tt.genres, testTaxonomy)
+
+			if tt.wantErr {
+				if _, ok := v.Errors["genres"]; !ok {
+					t.Fatalf("errors = %v, want one for genres", v.Errors)
+				}
+				return
+			}
+
+			if !v.Valid() {
+				t.Fatalf("unexpected errors: %v", v.Errors)
+			}
+
+			if !reflect.DeepEqual(tt.genres, tt.want) {
+				t.Errorf("got %v, want %v", tt.genres, tt.want)
+			}
+		})
+	}
+}
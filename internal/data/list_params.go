@@ -12,6 +12,7 @@ type ListParams struct {
 	PageSize     int
 	Sort         string
 	SortSafelist []string
+	Cursor       string
 }
 
 type Metadata struct {
@@ -60,6 +61,26 @@ func (lp ListParams) sortDirection() string {
 	return "ASC"
 }
 
+func (lp ListParams) keysetOperator() string {
+	if strings.HasPrefix(lp.Sort, "-") {
+		return "<"
+	}
+	return ">"
+}
+
+func (lp ListParams) after() (cursor, bool) {
+	if lp.Cursor == "" {
+		return cursor{}, false
+	}
+
+	c, err := decodeCursor(lp.Cursor)
+	if err != nil || c.Sort != lp.Sort {
+		panic("unsafe cursor parameter: " + lp.Cursor)
+	}
+
+	return c, true
+}
+
 func ValidateListParams(v *validator.Validator, p ListParams) {
 	v.Check(p.Page > 0, "page", "must be greater than zero")
 	v.Check(p.Page <= 10_000_000, "page", "must be a maximum of 10 million")
@@ -67,3 +88,15 @@ func ValidateListParams(v *validator.Validator, p ListParams) {
 	v.Check(p.PageSize <= 100, "page_size", "must be a maximum of 100")
 	v.Check(validator.In(p.Sort, p.SortSafelist...), "sort", "invalid sort value")
 }
+
+func ValidateCursorListParams(v *validator.Validator, p ListParams) {
+	v.Check(p.PageSize > 0, "page_size", "must be greater than zero")
+	v.Check(p.PageSize <= 100, "page_size", "must be a maximum of 100")
+	v.Check(validator.In(p.Sort, p.SortSafelist...), "sort", "invalid sort value")
+
+	if p.Cursor != "" {
+		c, err := decodeCursor(p.Cursor)
+		v.Check(err == nil, "cursor", "must be a valid cursor")
+		v.Check(err != nil || c.Sort == p.Sort, "cursor", "was issued for a different sort value")
+	}
+}
@@ -28,28 +28,129 @@ type Models struct {
 		DeleteAllForUser(scope string, userID int64) error
 	}
 	Movies interface {
-		GetMany(title string, genres []string, lp ListParams) ([]*Movie, Metadata, error)
-		Insert(movie *Movie) error
+		GetMany(f MovieFilters, lp ListParams) ([]*Movie, Metadata, error)
+		GetManyByCursor(f MovieFilters, lp ListParams) ([]*Movie, CursorMetadata, error)
+		GetFacets(f MovieFilters) (MovieFacets, error)
+		Export(f MovieFilters, lp ListParams, fn func(*Movie) error) error
+		HasTitleMatches(f MovieFilters) (bool, error)
+		GetSuggestions(title string, limit int) ([]string, error)
+		Insert(movie *Movie, userID int64) error
+		InsertMany(movies []*Movie, userID int64) error
 		Get(id int64) (*Movie, error)
-		Update(movie *Movie) error
+		GetByIDs(ids []int64) ([]*Movie, error)
+		GetByExternalID(source, externalID string) (*Movie, error)
+		MatchFilters(ids []int64, f MovieFilters) ([]int64, error)
+		Update(movie *Movie, userID int64) error
+		SetPoster(id int64, previous, urls PosterURLs) error
+		Delete(id int64, version int32) error
+		GetDeleted(lp ListParams) ([]*Movie, Metadata, error)
+		Restore(id int64) (*Movie, error)
+		PurgeDeleted(retention time.Duration) ([]PosterURLs, error)
+		FindDuplicates(movie *Movie, fuzzy bool) ([]int64, error)
+		Merge(survivorID, duplicateID int64) (PosterURLs, error)
+		GetRedirects(ids []int64) (map[int64]int64, error)
+		GetRedirect(id int64) (int64, error)
+		GetStats(newest int) (MovieStats, error)
+	}
+	MovieRevisions interface {
+		GetAllForMovie(movieID int64) ([]*MovieRevision, error)
+		Get(movieID int64, version int32) (*MovieRevision, error)
+	}
+	MovieTranslations interface {
+		GetForMovie(movieID int64) ([]*MovieTranslation, error)
+		GetForMovies(movieIDs []int64, languages []string) (map[int64][]*MovieTranslation, error)
+		Upsert(t *MovieTranslation) error
+		Delete(movieID int64, locale string) error
+	}
+	MovieNeighbours interface {
+		GetForMovie(movieID int64, limit int) ([]*SimilarMovie, error)
+		GetCandidates(movie *Movie, limit int) ([]*Movie, error)
+		GetInputs(minCommon, perMovie int) ([]*Movie, []CoRating, error)
+		Replace(neighbours []Neighbour) error
+	}
+	Ratings interface {
+		Upsert(rating *Rating) error
+		GetReviewsForMovie(movieID int64, lp ListParams) ([]*Rating, Metadata, error)
+		GetLatestReviewsForMovies(movieIDs []int64, limit int) (map[int64][]*Rating, error)
+	}
+	Watchlists interface {
+		GetForUser(userID int64) ([]*WatchlistItem, error)
+		Add(userID, movieID int64) error
+		Remove(userID, movieID int64) error
+		Reorder(userID int64, movieIDs []int64) error
+	}
+	SavedSearches interface {
+		Insert(s *SavedSearch) error
+		GetForUser(userID int64) ([]*SavedSearch, error)
+		GetAll() ([]*SavedSearch, error)
+		Get(id, userID int64) (*SavedSearch, error)
+		Update(s *SavedSearch) error
+		Delete(id, userID int64) error
+		GetQueued(limit int) ([]int64, error)
+		Dequeue(movieIDs []int64) error
+		AddMatches(searchIDs, movieIDs []int64) error
+		GetPendingAlerts(frequency string) ([]*SavedSearchAlert, error)
+		MarkNotified(alerts []*SavedSearchAlert) error
+	}
+	WatchHistory interface {
+		Insert(entry *WatchEntry) error
+		GetForUser(userID int64, lp ListParams) ([]*WatchEntry, Metadata, error)
+		GetSummary(userID int64) (*WatchSummary, error)
+	}
+	Genres interface {
+		GetAll() ([]*Genre, error)
+		GetTaxonomy() (GenreTaxonomy, error)
+	}
+	People interface {
+		GetMany(name string, lp ListParams) ([]*Person, Metadata, error)
+		Insert(person *Person) error
+		Get(id int64) (*Person, error)
+		Update(person *Person) error
+		Delete(id int64) error
+	}
+	Credits interface {
+		Insert(credit *Credit) error
+		GetForMovie(movieID int64) ([]*Credit, error)
+		GetForMovies(movieIDs []int64) (map[int64][]*Credit, error)
+		GetFilmography(personID int64) ([]*Credit, error)
 		Delete(id int64) error
 	}
 }
 
 func NewModels(db *sql.DB) *Models {
 	return &Models{
-		Users:       UserModel{DB: db},
-		Permissions: PermissionModel{DB: db},
-		Tokens:      TokenModel{DB: db},
-		Movies:      MovieModel{DB: db},
+		Users:             UserModel{DB: db},
+		Permissions:       PermissionModel{DB: db},
+		Tokens:            TokenModel{DB: db},
+		Movies:            MovieModel{DB: db},
+		MovieRevisions:    MovieRevisionModel{DB: db},
+		MovieTranslations: MovieTranslationModel{DB: db},
+		MovieNeighbours:   MovieNeighbourModel{DB: db},
+		Ratings:           RatingModel{DB: db},
+		Watchlists:        WatchlistModel{DB: db},
+		SavedSearches:     SavedSearchModel{DB: db},
+		WatchHistory:      WatchHistoryModel{DB: db},
+		Genres:            GenreModel{DB: db},
+		People:            PersonModel{DB: db},
+		Credits:           CreditModel{DB: db},
 	}
 }
 
 func NewMockModels() Models {
 	return Models{
-		Users:       MockUserModel{},
-		Permissions: MockPermissionModel{},
-		Tokens:      MockTokenModel{},
-		Movies:      MockMovieModel{},
+		Users:             MockUserModel{},
+		Permissions:       MockPermissionModel{},
+		Tokens:            MockTokenModel{},
+		Movies:            MockMovieModel{},
+		MovieRevisions:    MockMovieRevisionModel{},
+		MovieTranslations: MockMovieTranslationModel{},
+		MovieNeighbours:   MockMovieNeighbourModel{},
+		Ratings:           MockRatingModel{},
+		Watchlists:        MockWatchlistModel{},
+		SavedSearches:     MockSavedSearchModel{},
+		WatchHistory:      MockWatchHistoryModel{},
+		Genres:            MockGenreModel{},
+		People:            MockPersonModel{},
+		Credits:           MockCreditModel{},
 	}
 }
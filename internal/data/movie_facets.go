@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type MovieFacets struct {
+	Genres   []FacetCount `json:"genres"`
+	Decades  []FacetCount `json:"decades"`
+	Runtimes []FacetCount `json:"runtimes"`
+}
+
+func (m MovieModel) GetFacets(f MovieFilters) (MovieFacets, error) {
+	conditions, args := f.where()
+
+	query := `
+	WITH filtered AS (
+		SELECT genres, year, runtime
+		 FROM movies
+		WHERE 1 = 1 ` + conditions + `
+	)
+	SELECT 'genre', g, count(*) FROM filtered, unnest(genres) g GROUP BY g
+	UNION ALL
+	SELECT 'decade', (year / 10 * 10)::text || 's', count(*) FROM filtered GROUP BY year / 10
+	UNION ALL
+	SELECT 'runtime', CASE
+		WHEN runtime < 90 THEN 'under 90 mins'
+		WHEN runtime < 120 THEN '90-119 mins'
+		WHEN runtime < 150 THEN '120-149 mins'
+		ELSE '150+ mins'
+	END AS bucket, count(*) FROM filtered GROUP BY bucket
+	ORDER BY 1, 3 DESC, 2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return MovieFacets{}, err
+	}
+	defer rows.Close()
+
+	facets := MovieFacets{
+		Genres:   []FacetCount{},
+		Decades:  []FacetCount{},
+		Runtimes: []FacetCount{},
+	}
+
+	for rows.Next() {
+		var kind string
+		var fc FacetCount
+
+		err := rows.Scan(&kind, &fc.Value, &fc.Count)
+		if err != nil {
+			return MovieFacets{}, err
+		}
+
+		switch kind {
+		case "genre":
+			facets.Genres = append(facets.Genres, fc)
+		case "decade":
+			facets.Decades = append(facets.Decades, fc)
+		case "runtime":
+			facets.Runtimes = append(facets.Runtimes, fc)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return MovieFacets{}, err
+	}
+
+	return facets, nil
+}
+
+func (m MockMovieModel) GetFacets(f MovieFilters) (MovieFacets, error) {
+	return MovieFacets{}, nil
+}
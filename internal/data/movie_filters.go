@@ -0,0 +1,161 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var (
+	SearchLanguages = []string{"simple", "english", "russian", "german", "french", "spanish"}
+	GenresModes     = []string{"all", "any", "none"}
+)
+
+type MovieFilters struct {
+	Title          string
+	Genres         []string
+	GenresMode     string
+	ExcludedGenres []string
+	YearFrom       int
+	YearTo         int
+	RuntimeMin     int
+	RuntimeMax     int
+	PersonID       int64
+	Language       string
+	// Fuzzy switches the title search to trigram similarity. It isn't read
+	// from the query string: listings fall back to it when the full-text
+	// search finds no titles at all.
+	Fuzzy bool
+}
+
+func ValidateMovieFilters(v *validator.Validator, f MovieFilters, genres GenreTaxonomy) {
+	canonicalizeGenres(v, "genres", f.Genres, genres)
+	canonicalizeGenres(v, "-genres", f.ExcludedGenres, genres)
+
+	v.Check(len(f.Title) <= 500, "title", "must not be more than 500 bytes long")
+	v.Check(validator.In(f.Language, SearchLanguages...), "language", "invalid language value")
+	v.Check(validator.In(f.GenresMode, GenresModes...), "genres_mode", "invalid genres_mode value")
+	v.Check(f.YearFrom >= 0, "year_from", "must not be negative")
+	v.Check(f.YearTo >= 0, "year_to", "must not be negative")
+	v.Check(f.YearTo == 0 || f.YearFrom <= f.YearTo, "year_to", "must not be less than year_from")
+	v.Check(f.RuntimeMin >= 0, "runtime_min", "must not be negative")
+	v.Check(f.RuntimeMax >= 0, "runtime_max", "must not be negative")
+	v.Check(f.RuntimeMax == 0 || f.RuntimeMin <= f.RuntimeMax, "runtime_max", "must not be less than runtime_min")
+	v.Check(f.PersonID >= 0, "person", "must be a positive integer")
+}
+
+func (f MovieFilters) searchConfig() string {
+	if f.Language == "" {
+		return "simple"
+	}
+	for _, safeValue := range SearchLanguages {
+		if f.Language == safeValue {
+			return f.Language
+		}
+	}
+	panic("unsafe language parameter: " + f.Language)
+}
+
+func (f MovieFilters) tsquery() string {
+	words := strings.FieldsFunc(f.Title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i := range words {
+		words[i] = words[i] + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+func (f MovieFilters) genresOperator() string {
+	switch f.GenresMode {
+	case "any":
+		return "genres && $2"
+	case "none":
+		return "NOT genres && $2"
+	default:
+		return "genres @> $2"
+	}
+}
+
+func (f MovieFilters) where() (string, []interface{}) {
+	conditions := `
+	  AND deleted_at IS NULL`
+	var args []interface{}
+
+	if f.Fuzzy {
+		conditions += `
+	  AND ($1 <% title OR $1 = ''
+	    OR EXISTS (SELECT 1 FROM movie_translations t WHERE t.movie_id = movies.id AND $1 <% t.title))`
+		args = append(args, f.Title)
+	} else {
+		// Translations are searched and indexed with the simple
+		// configuration whatever their language.
+		conditions += fmt.Sprintf(`
+	  AND (to_tsvector('%[1]s', title) @@ to_tsquery('%[1]s', $1) OR $1 = ''
+	    OR EXISTS (SELECT 1 FROM movie_translations t WHERE t.movie_id = movies.id AND to_tsvector('simple', t.title) @@ to_tsquery('simple', $1)))`, f.searchConfig())
+		args = append(args, f.tsquery())
+	}
+
+	conditions += fmt.Sprintf(`
+	  AND (%s OR $2 = '{}')`, f.genresOperator())
+	args = append(args, pq.Array(f.Genres))
+
+	if len(f.ExcludedGenres) > 0 {
+		args = append(args, pq.Array(f.ExcludedGenres))
+		conditions += fmt.Sprintf(`
+	  AND NOT genres && $%d`, len(args))
+	}
+
+	if f.YearFrom > 0 {
+		args = append(args, f.YearFrom)
+		conditions += fmt.Sprintf(`
+	  AND year >= $%d`, len(args))
+	}
+
+	if f.YearTo > 0 {
+		args = append(args, f.YearTo)
+		conditions += fmt.Sprintf(`
+	  AND year <= $%d`, len(args))
+	}
+
+	if f.RuntimeMin > 0 {
+		args = append(args, f.RuntimeMin)
+		conditions += fmt.Sprintf(`
+	  AND runtime >= $%d`, len(args))
+	}
+
+	if f.RuntimeMax > 0 {
+		args = append(args, f.RuntimeMax)
+		conditions += fmt.Sprintf(`
+	  AND runtime <= $%d`, len(args))
+	}
+
+	if f.PersonID > 0 {
+		args = append(args, f.PersonID)
+		conditions += fmt.Sprintf(`
+	  AND EXISTS (SELECT 1 FROM credits c WHERE c.movie_id = movies.id AND c.person_id = $%d)`, len(args))
+	}
+
+	return conditions, args
+}
+
+func (f MovieFilters) rank() string {
+	if f.Fuzzy {
+		return `GREATEST(word_similarity($1, title),
+	    (SELECT max(word_similarity($1, t.title)) FROM movie_translations t WHERE t.movie_id = movies.id))`
+	}
+	return fmt.Sprintf(`GREATEST(ts_rank(to_tsvector('%[1]s', title), to_tsquery('%[1]s', $1)),
+	    (SELECT max(ts_rank(to_tsvector('simple', t.title), to_tsquery('simple', $1))) FROM movie_translations t WHERE t.movie_id = movies.id))`, f.searchConfig())
+}
+
+func (f MovieFilters) headline() string {
+	if f.Fuzzy {
+		return `''`
+	}
+	return fmt.Sprintf(`CASE WHEN $1 = '' THEN '' ELSE ts_headline('%[1]s', title, to_tsquery('%[1]s', $1)) END`, f.searchConfig())
+}
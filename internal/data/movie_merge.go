@@ -0,0 +1,209 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (m MovieModel) FindDuplicates(movie *Movie, fuzzy bool) ([]int64, error) {
+	query := `
+	SELECT id
+	FROM movies
+	WHERE deleted_at IS NULL
+	  AND id <> $4
+	  AND ((trim(regexp_replace(lower(title), '[^[:alnum:]]+', ' ', 'g')) = trim(regexp_replace(lower($1), '[^[:alnum:]]+', ' ', 'g')) AND year = $2)
+	    OR ($3 AND title % $1 AND similarity(title, $1) >= 0.6 AND year BETWEEN $2 - 1 AND $2 + 1))
+	ORDER BY id ASC
+	LIMIT 10`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movie.Title, movie.Year, fuzzy, movie.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Merge keeps the duplicate's revisions under its old ID. Its poster files are
+// left for the caller to remove once the merge has committed.
+func (m MovieModel) Merge(survivorID, duplicateID int64) (PosterURLs, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+	SELECT count(*)
+	FROM (
+		SELECT id
+		FROM movies
+		WHERE id = ANY($1) AND deleted_at IS NULL
+		FOR UPDATE
+	) locked`
+
+	var count int
+
+	err = tx.QueryRowContext(ctx, query, pq.Array([]int64{survivorID, duplicateID})).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+
+	if count != 2 {
+		return nil, ErrRecordNotFound
+	}
+
+	queries := []string{
+		`UPDATE movie_ratings SET movie_id = $1
+		WHERE movie_id = $2 AND user_id NOT IN (SELECT user_id FROM movie_ratings WHERE movie_id = $1)`,
+		`UPDATE watchlist_items SET movie_id = $1
+		WHERE movie_id = $2 AND user_id NOT IN (SELECT user_id FROM watchlist_items WHERE movie_id = $1)`,
+		`UPDATE watch_history SET movie_id = $1
+		WHERE movie_id = $2`,
+		`UPDATE credits c SET movie_id = $1
+		WHERE movie_id = $2 AND NOT EXISTS (SELECT 1 FROM credits s WHERE s.movie_id = $1 AND s.person_id = c.person_id AND s.role = c.role)`,
+		`UPDATE movie_translations SET movie_id = $1
+		WHERE movie_id = $2 AND locale NOT IN (SELECT locale FROM movie_translations WHERE movie_id = $1)`,
+		`UPDATE movie_external_ids SET movie_id = $1
+		WHERE movie_id = $2 AND source NOT IN (SELECT source FROM movie_external_ids WHERE movie_id = $1)`,
+		`UPDATE movie_redirects SET movie_id = $1
+		WHERE movie_id = $2`,
+		`INSERT INTO movie_redirects (old_id, movie_id)
+		VALUES ($2, $1)`,
+		// The rating aggregate trigger only follows changes to a rating, not
+		// ratings moving between movies, so recount the survivor's.
+		`UPDATE movies
+		SET rating_count = r.count, rating_total = r.total, average_rating = COALESCE(r.total::numeric / NULLIF(r.count, 0), 0)
+		FROM (SELECT count(*) AS count, COALESCE(sum(rating), 0) AS total FROM movie_ratings WHERE movie_id = $1) r
+		WHERE id = $1`,
+	}
+
+	for _, query := range queries {
+		_, err = tx.ExecContext(ctx, query, survivorID, duplicateID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = `
+	DELETE FROM movies
+	WHERE id = $1
+	RETURNING poster_urls`
+
+	var posters PosterURLs
+
+	err = tx.QueryRowContext(ctx, query, duplicateID).Scan(&posters)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return posters, nil
+}
+
+func (m MovieModel) GetRedirects(ids []int64) (map[int64]int64, error) {
+	query := `
+	SELECT old_id, movie_id
+	FROM movie_redirects
+	WHERE old_id = ANY($1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redirects := make(map[int64]int64)
+
+	for rows.Next() {
+		var oldID, movieID int64
+
+		err := rows.Scan(&oldID, &movieID)
+		if err != nil {
+			return nil, err
+		}
+
+		redirects[oldID] = movieID
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return redirects, nil
+}
+
+func (m MovieModel) GetRedirect(id int64) (int64, error) {
+	query := `
+	SELECT movie_id
+	FROM movie_redirects
+	WHERE old_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var movieID int64
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&movieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return movieID, nil
+}
+
+func (m MockMovieModel) FindDuplicates(movie *Movie, fuzzy bool) ([]int64, error) {
+	return nil, nil
+}
+
+func (m MockMovieModel) Merge(survivorID, duplicateID int64) (PosterURLs, error) {
+	return nil, nil
+}
+
+func (m MockMovieModel) GetRedirects(ids []int64) (map[int64]int64, error) {
+	return map[int64]int64{}, nil
+}
+
+func (m MockMovieModel) GetRedirect(id int64) (int64, error) {
+	return 0, ErrRecordNotFound
+}
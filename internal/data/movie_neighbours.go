@@ -0,0 +1,279 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type MovieNeighbourModel struct {
+	DB *sql.DB
+}
+
+func (m MovieNeighbourModel) GetForMovie(movieID int64, limit int) ([]*SimilarMovie, error) {
+	query := `
+	SELECT m.id, m.title, m.year, m.runtime, m.genres, m.created_at, m.version, m.average_rating, m.rating_count, m.poster_urls, m.original_locale, ` + externalIDsColumn("m") + `, n.score
+	FROM movie_neighbours n
+	INNER JOIN movies m
+	ON m.id = n.neighbour_id
+	WHERE n.movie_id = $1
+	  AND m.deleted_at IS NULL
+	ORDER BY n.score DESC, m.id ASC
+	LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	similar := []*SimilarMovie{}
+
+	for rows.Next() {
+		var movie SimilarMovie
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.CreatedAt,
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+			&movie.Similarity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		similar = append(similar, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return similar, nil
+}
+
+func (m MovieNeighbourModel) GetCandidates(movie *Movie, limit int) ([]*Movie, error) {
+	query := `
+	SELECT id, title, year, runtime, genres, created_at, version, average_rating, rating_count, poster_urls, original_locale, ` + externalIDsColumn("movies") + `
+	FROM movies
+	WHERE genres && $1
+	  AND id <> $2
+	  AND deleted_at IS NULL
+	ORDER BY rating_count DESC, id ASC
+	LIMIT $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(movie.Genres), movie.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.CreatedAt,
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+// GetInputs scores co-rated pairs by the cosine similarity of their raters'
+// mean-centred ratings, mapped from [-1, 1] onto [0, 1].
+func (m MovieNeighbourModel) GetInputs(minCommon, perMovie int) ([]*Movie, []CoRating, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	query := `
+	SELECT id, year, runtime, genres
+	FROM movies
+	WHERE deleted_at IS NULL`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(&movie.ID, &movie.Year, &movie.Runtime, pq.Array(&movie.Genres))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	query = `
+	WITH centred AS (
+		SELECT r.user_id, r.movie_id, r.rating - avg(r.rating) OVER (PARTITION BY r.user_id) AS rating
+		FROM movie_ratings r
+		INNER JOIN movies m
+		ON m.id = r.movie_id
+		WHERE m.deleted_at IS NULL
+	), pairs AS (
+		SELECT a.movie_id, b.movie_id AS other_id,
+		    sum(a.rating * b.rating) / sqrt(sum(a.rating * a.rating) * sum(b.rating * b.rating)) AS cosine
+		FROM centred a
+		INNER JOIN centred b
+		ON b.user_id = a.user_id AND b.movie_id <> a.movie_id
+		GROUP BY a.movie_id, b.movie_id
+		HAVING count(*) >= $1
+		   AND sum(a.rating * a.rating) > 0
+		   AND sum(b.rating * b.rating) > 0
+	), ranked AS (
+		SELECT movie_id, other_id, cosine,
+		    row_number() OVER (PARTITION BY movie_id ORDER BY cosine DESC, other_id ASC) AS rank
+		FROM pairs
+	)
+	SELECT movie_id, other_id, ((cosine + 1) / 2)::double precision
+	FROM ranked
+	WHERE rank <= $2`
+
+	coRatingRows, err := m.DB.QueryContext(ctx, query, minCommon, perMovie)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer coRatingRows.Close()
+
+	coRatings := []CoRating{}
+
+	for coRatingRows.Next() {
+		var c CoRating
+
+		err := coRatingRows.Scan(&c.MovieID, &c.OtherID, &c.Score)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		coRatings = append(coRatings, c)
+	}
+
+	if err = coRatingRows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return movies, coRatings, nil
+}
+
+// Replace drops pairs naming a movie purged since the rebuild started.
+func (m MovieNeighbourModel) Replace(neighbours []Neighbour) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `CREATE TEMPORARY TABLE movie_neighbours_new (LIKE movie_neighbours) ON COMMIT DROP`)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("movie_neighbours_new", "movie_id", "neighbour_id", "score"))
+	if err != nil {
+		return err
+	}
+
+	for _, n := range neighbours {
+		_, err = stmt.ExecContext(ctx, n.MovieID, n.NeighbourID, n.Score)
+		if err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		stmt.Close()
+		return err
+	}
+
+	err = stmt.Close()
+	if err != nil {
+		return err
+	}
+
+	query := `
+	DELETE FROM movie_neighbours;
+	INSERT INTO movie_neighbours (movie_id, neighbour_id, score)
+	SELECT n.movie_id, n.neighbour_id, n.score
+	FROM movie_neighbours_new n
+	INNER JOIN movies a ON a.id = n.movie_id
+	INNER JOIN movies b ON b.id = n.neighbour_id`
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type MockMovieNeighbourModel struct{}
+
+func (m MockMovieNeighbourModel) GetForMovie(movieID int64, limit int) ([]*SimilarMovie, error) {
+	return nil, nil
+}
+
+func (m MockMovieNeighbourModel) GetCandidates(movie *Movie, limit int) ([]*Movie, error) {
+	return nil, nil
+}
+
+func (m MockMovieNeighbourModel) GetInputs(minCommon, perMovie int) ([]*Movie, []CoRating, error) {
+	return nil, nil, nil
+}
+
+func (m MockMovieNeighbourModel) Replace(neighbours []Neighbour) error {
+	return nil
+}
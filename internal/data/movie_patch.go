@@ -0,0 +1,239 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"greenlight.aenkas.org/internal/validator"
+)
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func ApplyMergePatch(v *validator.Validator, movie *Movie, patch map[string]json.RawMessage) {
+	for key, value := range patch {
+		isNull := string(value) == "null"
+
+		switch key {
+		case "title":
+			movie.Title = ""
+			if !isNull && json.Unmarshal(value, &movie.Title) != nil {
+				v.AddError(key, "must be a string")
+			}
+		case "year":
+			movie.Year = 0
+			if !isNull && json.Unmarshal(value, &movie.Year) != nil {
+				v.AddError(key, "must be an integer")
+			}
+		case "runtime":
+			movie.Runtime = 0
+			if !isNull {
+				if err := json.Unmarshal(value, &movie.Runtime); err != nil {
+					v.AddError(key, err.Error())
+				}
+			}
+		case "genres":
+			movie.Genres = nil
+			if !isNull && json.Unmarshal(value, &movie.Genres) != nil {
+				v.AddError(key, "must be an array of strings")
+			}
+		case "original_locale":
+			movie.OriginalLocale = ""
+			if !isNull && json.Unmarshal(value, &movie.OriginalLocale) != nil {
+				v.AddError(key, "must be a string")
+			}
+		case "external_ids":
+			// external_ids is itself merged: null clears it, and a null
+			// source within it removes just that source.
+			var changes map[string]*string
+			if !isNull && json.Unmarshal(value, &changes) != nil {
+				v.AddError(key, "must be an object of strings")
+				continue
+			}
+			if isNull {
+				movie.ExternalIDs = ExternalIDs{}
+				continue
+			}
+			movie.ExternalIDs = movie.ExternalIDs.Merge(changes)
+		default:
+			v.AddError(key, "is not a patchable field")
+		}
+	}
+}
+
+func ApplyJSONPatch(v *validator.Validator, movie *Movie, operations []PatchOperation, genres GenreTaxonomy) {
+	for i, op := range operations {
+		err := applyPatchOperation(movie, op, genres)
+		if err != nil {
+			v.AddError(fmt.Sprintf("patch[%d]", i), err.Error())
+			return
+		}
+	}
+}
+
+func applyPatchOperation(movie *Movie, op PatchOperation, genres GenreTaxonomy) error {
+	if !validator.In(op.Op, "add", "remove", "replace", "test") {
+		return fmt.Errorf("unsupported op %q, must be one of add, remove, replace or test", op.Op)
+	}
+
+	if op.Op != "remove" && op.Value == nil {
+		return errors.New("value must be provided")
+	}
+
+	segments := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+	if !strings.HasPrefix(op.Path, "/") || len(segments) > 2 || (len(segments) == 2 && !validator.In(segments[0], "genres", "external_ids")) {
+		return fmt.Errorf("path %q does not exist", op.Path)
+	}
+
+	if segments[0] == "external_ids" {
+		return applyExternalIDsPatchOperation(movie, op, segments[1:])
+	}
+
+	if len(segments) == 2 {
+		return applyGenrePatchOperation(movie, op, segments[1], genres)
+	}
+
+	var field interface{}
+
+	switch segments[0] {
+	case "title":
+		field = &movie.Title
+	case "year":
+		field = &movie.Year
+	case "runtime":
+		field = &movie.Runtime
+	case "genres":
+		field = &movie.Genres
+	case "original_locale":
+		field = &movie.OriginalLocale
+	default:
+		return fmt.Errorf("path %q does not exist", op.Path)
+	}
+
+	target := reflect.ValueOf(field).Elem()
+
+	switch op.Op {
+	case "remove":
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	case "test":
+		value := reflect.New(target.Type())
+		if err := json.Unmarshal(op.Value, value.Interface()); err != nil {
+			return fmt.Errorf("value for %q is invalid: %s", op.Path, err)
+		}
+		if segments[0] == "genres" {
+			if !genres.sameGenres(movie.Genres, *value.Interface().(*[]string)) {
+				return fmt.Errorf("test failed for path %q", op.Path)
+			}
+			return nil
+		}
+		if !reflect.DeepEqual(value.Elem().Interface(), target.Interface()) {
+			return fmt.Errorf("test failed for path %q", op.Path)
+		}
+		return nil
+	default:
+		value := reflect.New(target.Type())
+		if err := json.Unmarshal(op.Value, value.Interface()); err != nil {
+			return fmt.Errorf("value for %q is invalid: %s", op.Path, err)
+		}
+		target.Set(value.Elem())
+		return nil
+	}
+}
+
+func applyGenrePatchOperation(movie *Movie, op PatchOperation, index string, genres GenreTaxonomy) error {
+	var genre string
+
+	if op.Op != "remove" {
+		if err := json.Unmarshal(op.Value, &genre); err != nil {
+			return fmt.Errorf("value for %q must be a string", op.Path)
+		}
+	}
+
+	if index == "-" && op.Op == "add" {
+		movie.Genres = append(movie.Genres, genre)
+		return nil
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i > len(movie.Genres) || (i == len(movie.Genres) && op.Op != "add") {
+		return fmt.Errorf("path %q does not exist", op.Path)
+	}
+
+	switch op.Op {
+	case "add":
+		movie.Genres = append(movie.Genres[:i], append([]string{genre}, movie.Genres[i:]...)...)
+	case "remove":
+		movie.Genres = append(movie.Genres[:i], movie.Genres[i+1:]...)
+	case "replace":
+		movie.Genres[i] = genre
+	case "test":
+		if !genres.sameGenres(movie.Genres[i:i+1], []string{genre}) {
+			return fmt.Errorf("test failed for path %q", op.Path)
+		}
+	}
+
+	return nil
+}
+
+func applyExternalIDsPatchOperation(movie *Movie, op PatchOperation, source []string) error {
+	if len(source) == 0 {
+		var ids ExternalIDs
+
+		if op.Op != "remove" {
+			if err := json.Unmarshal(op.Value, &ids); err != nil {
+				return fmt.Errorf("value for %q must be an object of strings", op.Path)
+			}
+		}
+
+		switch op.Op {
+		case "remove":
+			movie.ExternalIDs = ExternalIDs{}
+		case "test":
+			if !movie.ExternalIDs.equal(ids) {
+				return fmt.Errorf("test failed for path %q", op.Path)
+			}
+		default:
+			movie.ExternalIDs = ExternalIDs{}
+			for s, id := range ids {
+				movie.ExternalIDs[s] = id
+			}
+		}
+
+		return nil
+	}
+
+	var externalID string
+
+	if op.Op != "remove" {
+		if err := json.Unmarshal(op.Value, &externalID); err != nil {
+			return fmt.Errorf("value for %q must be a string", op.Path)
+		}
+	}
+
+	current, exists := movie.ExternalIDs[source[0]]
+	if !exists && op.Op != "add" {
+		return fmt.Errorf("path %q does not exist", op.Path)
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		movie.ExternalIDs = movie.ExternalIDs.Merge(map[string]*string{source[0]: &externalID})
+	case "remove":
+		movie.ExternalIDs = movie.ExternalIDs.Merge(map[string]*string{source[0]: nil})
+	case "test":
+		if current != externalID {
+			return fmt.Errorf("test failed for path %q", op.Path)
+		}
+	}
+
+	return nil
+}
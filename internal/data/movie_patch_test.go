@@ -0,0 +1,261 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"greenlight.aenkas.org/internal/validator"
+)
+
+func testMovie() *Movie {
+	return &Movie{
+		ID:          1,
+		Title:       "Alien",
+		Year:        1979,
+		Runtime:     117,
+		Genres:      []string{"sci-fi", "horror"},
+		ExternalIDs: ExternalIDs{"imdb": "tt0078748"},
+	}
+}
+
+var testTaxonomy = GenreTaxonomy{
+	"sci-fi":          "sci-fi",
+	"science-fiction": "sci-fi",
+	"horror":          "horror",
+	"drama":           "drama",
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    func(*Movie)
+		wantErr string
+	}{
+		{
+			name:  "title",
+			patch: `{"title": "Aliens"}`,
+			want:  func(m *Movie) { m.Title = "Aliens" },
+		},
+		{
+			name:  "null clears",
+			patch: `{"year": null}`,
+			want:  func(m *Movie) { m.Year = 0 },
+		},
+		{
+			name:  "runtime",
+			patch: `{"runtime": "2h"}`,
+			want:  func(m *Movie) { m.Runtime = 120 },
+		},
+		{
+			name:  "genres replaced whole",
+			patch: `{"genres": ["drama"]}`,
+			want:  func(m *Movie) { m.Genres = []string{"drama"} },
+		},
+		{
+			name:  "external id added",
+			patch: `{"external_ids": {"tmdb": "348"}}`,
+			want:  func(m *Movie) { m.ExternalIDs = ExternalIDs{"imdb": "tt0078748", "tmdb": "348"} },
+		},
+		{
+			name:  "external id removed",
+			patch: `{"external_ids": {"imdb": null}}`,
+			want:  func(m *Movie) { m.ExternalIDs = ExternalIDs{} },
+		},
+		{
+			name:  "external ids cleared",
+			patch: `{"external_ids": null}`,
+			want:  func(m *Movie) { m.ExternalIDs = ExternalIDs{} },
+		},
+		{
+			name:    "wrong type",
+			patch:   `{"year": "1979"}`,
+			wantErr: "year",
+		},
+		{
+			name:    "unknown field",
+			patch:   `{"version": 2}`,
+			wantErr: "version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+
+			v := validator.New()
+			got := testMovie()
+
+			ApplyMergePatch(v, got, patch)
+
+			if tt.wantErr != "" {
+				if _, ok := v.Errors[tt.wantErr]; !ok {
+					t.Fatalf("errors = %v, want one for %q", v.Errors, tt.wantErr)
+				}
+				return
+			}
+
+			if !v.Valid() {
+				t.Fatalf("unexpected errors: %v", v.Errors)
+			}
+
+			want := testMovie()
+			tt.want(want)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    func(*Movie)
+		wantErr bool
+	}{
+		{
+			name:  "replace title",
+			patch: `[{"op": "replace", "path": "/title", "value": "Aliens"}]`,
+			want:  func(m *Movie) { m.Title = "Aliens" },
+		},
+		{
+			name:  "remove year",
+			patch: `[{"op": "remove", "path": "/year"}]`,
+			want:  func(m *Movie) { m.Year = 0 },
+		},
+		{
+			name:  "append genre",
+			patch: `[{"op": "add", "path": "/genres/-", "value": "drama"}]`,
+			want:  func(m *Movie) { m.Genres = []string{"sci-fi", "horror", "drama"} },
+		},
+		{
+			name:  "insert genre",
+			patch: `[{"op": "add", "path": "/genres/0", "value": "drama"}]`,
+			want:  func(m *Movie) { m.Genres = []string{"drama", "sci-fi", "horror"} },
+		},
+		{
+			name:  "remove genre",
+			patch: `[{"op": "remove", "path": "/genres/1"}]`,
+			want:  func(m *Movie) { m.Genres = []string{"sci-fi"} },
+		},
+		{
+			name:  "replace genre",
+			patch: `[{"op": "replace", "path": "/genres/1", "value": "drama"}]`,
+			want:  func(m *Movie) { m.Genres = []string{"sci-fi", "drama"} },
+		},
+		{
+			name:    "genre index out of range",
+			patch:   `[{"op": "remove", "path": "/genres/2"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "test genres by alias",
+			patch: `[{"op": "test", "path": "/genres", "value": ["Science Fiction", "Horror"]}, {"op": "replace", "path": "/title", "value": "Aliens"}]`,
+			want:  func(m *Movie) { m.Title = "Aliens" },
+		},
+		{
+			name:  "test genre by alias",
+			patch: `[{"op": "test", "path": "/genres/0", "value": "science_fiction"}]`,
+			want:  func(m *Movie) {},
+		},
+		{
+			name:  "test genre added by alias",
+			patch: `[{"op": "add", "path": "/genres/-", "value": "Drama"}, {"op": "test", "path": "/genres/2", "value": "drama"}]`,
+			want:  func(m *Movie) { m.Genres = []string{"sci-fi", "horror", "Drama"} },
+		},
+		{
+			name:    "test genres in another order",
+			patch:   `[{"op": "test", "path": "/genres", "value": ["horror", "sci-fi"]}]`,
+			wantErr: true,
+		},
+		{
+			name:    "test unknown genre",
+			patch:   `[{"op": "test", "path": "/genres/0", "value": "western"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "test title",
+			patch: `[{"op": "test", "path": "/title", "value": "Alien"}]`,
+			want:  func(m *Movie) {},
+		},
+		{
+			name:    "failed test stops the patch",
+			patch:   `[{"op": "test", "path": "/title", "value": "Aliens"}, {"op": "remove", "path": "/year"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "add external id",
+			patch: `[{"op": "add", "path": "/external_ids/tmdb", "value": "348"}]`,
+			want:  func(m *Movie) { m.ExternalIDs = ExternalIDs{"imdb": "tt0078748", "tmdb": "348"} },
+		},
+		{
+			name:    "replace missing external id",
+			patch:   `[{"op": "replace", "path": "/external_ids/tmdb", "value": "348"}]`,
+			wantErr: true,
+		},
+		{
+			name:  "remove external ids",
+			patch: `[{"op": "remove", "path": "/external_ids"}]`,
+			want:  func(m *Movie) { m.ExternalIDs = ExternalIDs{} },
+		},
+		{
+			name:    "unsupported op",
+			patch:   `[{"op": "move", "from": "/title", "path": "/original_title"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			patch:   `[{"op": "replace", "path": "/title"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown path",
+			patch:   `[{"op": "replace", "path": "/version", "value": 2}]`,
+			wantErr: true,
+		},
+		{
+			name:    "nested path outside genres",
+			patch:   `[{"op": "replace", "path": "/title/0", "value": "A"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []PatchOperation
+			if err := json.Unmarshal([]byte(tt.patch), &operations); err != nil {
+				t.Fatal(err)
+			}
+
+			v := validator.New()
+			got := testMovie()
+
+			ApplyJSONPatch(v, got, operations, testTaxonomy)
+
+			if tt.wantErr {
+				if v.Valid() {
+					t.Fatalf("got no errors, want one")
+				}
+				return
+			}
+
+			if !v.Valid() {
+				t.Fatalf("unexpected errors: %v", v.Errors)
+			}
+
+			want := testMovie()
+			tt.want(want)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type MovieRevision struct {
+	MovieID        int64                  `json:"movie_id"`
+	Version        int32                  `json:"version"`
+	Title          string                 `json:"title"`
+	Year           int32                  `json:"year"`
+	Runtime        Runtime                `json:"runtime"`
+	Genres         []string               `json:"genres"`
+	ExternalIDs    ExternalIDs            `json:"external_ids"`
+	OriginalLocale string                 `json:"original_locale"`
+	ChangedBy      *int64                 `json:"changed_by"`
+	ChangedAt      time.Time              `json:"changed_at"`
+	Changes        map[string]FieldChange `json:"changes,omitempty"`
+}
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func (r *MovieRevision) Diff(prev *MovieRevision) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+
+	if prev == nil {
+		return changes
+	}
+
+	if r.Title != prev.Title {
+		changes["title"] = FieldChange{From: prev.Title, To: r.Title}
+	}
+	if r.Year != prev.Year {
+		changes["year"] = FieldChange{From: prev.Year, To: r.Year}
+	}
+	if r.Runtime != prev.Runtime {
+		changes["runtime"] = FieldChange{From: prev.Runtime, To: r.Runtime}
+	}
+	if !reflect.DeepEqual(r.Genres, prev.Genres) {
+		changes["genres"] = FieldChange{From: prev.Genres, To: r.Genres}
+	}
+	if !r.ExternalIDs.equal(prev.ExternalIDs) {
+		changes["external_ids"] = FieldChange{From: prev.ExternalIDs, To: r.ExternalIDs}
+	}
+	if r.OriginalLocale != prev.OriginalLocale {
+		changes["original_locale"] = FieldChange{From: prev.OriginalLocale, To: r.OriginalLocale}
+	}
+
+	return changes
+}
+
+func (r *MovieRevision) ApplyTo(movie *Movie) {
+	movie.Title = r.Title
+	movie.Year = r.Year
+	movie.Runtime = r.Runtime
+	movie.Genres = r.Genres
+	movie.OriginalLocale = r.OriginalLocale
+
+	// Non-nil, so that a revision without external IDs clears them.
+	movie.ExternalIDs = ExternalIDs{}
+	for source, externalID := range r.ExternalIDs {
+		movie.ExternalIDs[source] = externalID
+	}
+}
+
+type MovieRevisionModel struct {
+	DB *sql.DB
+}
+
+// insertRevisions must run in the transaction that wrote the movies, after
+// their external IDs, so that no version is missing from the history.
+func insertRevisions(ctx context.Context, tx *sql.Tx, movieIDs []int64, userID int64) error {
+	query := `
+	INSERT INTO movie_revisions (movie_id, version, title, year, runtime, genres, external_ids, original_locale, changed_by)
+	SELECT id, version, title, year, runtime, genres, ` + externalIDsColumn("movies") + `, original_locale, $2
+	FROM movies
+	WHERE id = ANY($1)`
+
+	_, err := tx.ExecContext(ctx, query, pq.Array(movieIDs), userID)
+	return err
+}
+
+// GetAllForMovie includes the revisions of movies merged into this one, which
+// keep their old movie ID.
+func (m MovieRevisionModel) GetAllForMovie(movieID int64) ([]*MovieRevision, error) {
+	query := `
+	SELECT movie_id, version, title, year, runtime, genres, external_ids, original_locale, changed_by, changed_at
+	FROM movie_revisions
+	WHERE movie_id = $1
+	   OR movie_id IN (SELECT old_id FROM movie_redirects WHERE movie_id = $1)
+	ORDER BY movie_id = $1, movie_id ASC, version ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*MovieRevision{}
+
+	for rows.Next() {
+		var revision MovieRevision
+
+		err := rows.Scan(
+			&revision.MovieID,
+			&revision.Version,
+			&revision.Title,
+			&revision.Year,
+			&revision.Runtime,
+			pq.Array(&revision.Genres),
+			&revision.ExternalIDs,
+			&revision.OriginalLocale,
+			&revision.ChangedBy,
+			&revision.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		if i > 0 && revisions[i].MovieID == revisions[i-1].MovieID {
+			revisions[i].Changes = revisions[i].Diff(revisions[i-1])
+		}
+	}
+
+	return revisions, nil
+}
+
+func (m MovieRevisionModel) Get(movieID int64, version int32) (*MovieRevision, error) {
+	query := `
+	SELECT movie_id, version, title, year, runtime, genres, external_ids, original_locale, changed_by, changed_at
+	FROM movie_revisions
+	WHERE movie_id = $1 AND version = $2`
+
+	var revision MovieRevision
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, movieID, version).Scan(
+		&revision.MovieID,
+		&revision.Version,
+		&revision.Title,
+		&revision.Year,
+		&revision.Runtime,
+		pq.Array(&revision.Genres),
+		&revision.ExternalIDs,
+		&revision.OriginalLocale,
+		&revision.ChangedBy,
+		&revision.ChangedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &revision, nil
+}
+
+type MockMovieRevisionModel struct{}
+
+func (m MockMovieRevisionModel) GetAllForMovie(movieID int64) ([]*MovieRevision, error) {
+	return nil, nil
+}
+
+func (m MockMovieRevisionModel) Get(movieID int64, version int32) (*MovieRevision, error) {
+	return nil, nil
+}
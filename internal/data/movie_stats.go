@@ -0,0 +1,152 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type RuntimePercentiles struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+}
+
+type MovieStats struct {
+	Total       int                `json:"total"`
+	Genres      []FacetCount       `json:"genres"`
+	Decades     []FacetCount       `json:"decades"`
+	Runtime     RuntimePercentiles `json:"runtime_percentiles"`
+	Newest      []*Movie           `json:"newest"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// GetStats runs its queries in one snapshot so the figures agree.
+func (m MovieModel) GetStats(newest int) (MovieStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return MovieStats{}, err
+	}
+	defer tx.Rollback()
+
+	stats := MovieStats{
+		Genres:      []FacetCount{},
+		Decades:     []FacetCount{},
+		Newest:      []*Movie{},
+		GeneratedAt: time.Now(),
+	}
+
+	query := `
+	SELECT count(*), COALESCE(percentile_cont(ARRAY[0.1, 0.25, 0.5, 0.75, 0.9]) WITHIN GROUP (ORDER BY runtime), '{}')
+	FROM movies
+	WHERE deleted_at IS NULL`
+
+	var percentiles pq.Float64Array
+
+	err = tx.QueryRowContext(ctx, query).Scan(&stats.Total, &percentiles)
+	if err != nil {
+		return MovieStats{}, err
+	}
+
+	if len(percentiles) == 5 {
+		stats.Runtime = RuntimePercentiles{
+			P10: percentiles[0],
+			P25: percentiles[1],
+			P50: percentiles[2],
+			P75: percentiles[3],
+			P90: percentiles[4],
+		}
+	}
+
+	query = `
+	WITH live AS (
+		SELECT genres, year
+		FROM movies
+		WHERE deleted_at IS NULL
+	)
+	SELECT 'genre', g, count(*) FROM live, unnest(genres) g GROUP BY g
+	UNION ALL
+	SELECT 'decade', (year / 10 * 10)::text || 's', count(*) FROM live GROUP BY year / 10
+	ORDER BY 1, 3 DESC, 2`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return MovieStats{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var fc FacetCount
+
+		err := rows.Scan(&kind, &fc.Value, &fc.Count)
+		if err != nil {
+			return MovieStats{}, err
+		}
+
+		switch kind {
+		case "genre":
+			stats.Genres = append(stats.Genres, fc)
+		case "decade":
+			stats.Decades = append(stats.Decades, fc)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return MovieStats{}, err
+	}
+
+	query = `
+	SELECT id, created_at, title, year, runtime, genres, version, average_rating, rating_count, poster_urls, original_locale, ` + externalIDsColumn("movies") + `
+	FROM movies
+	WHERE deleted_at IS NULL
+	ORDER BY created_at DESC, id DESC
+	LIMIT $1`
+
+	rows, err = tx.QueryContext(ctx, query, newest)
+	if err != nil {
+		return MovieStats{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+		)
+		if err != nil {
+			return MovieStats{}, err
+		}
+
+		stats.Newest = append(stats.Newest, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return MovieStats{}, err
+	}
+
+	return stats, tx.Commit()
+}
+
+func (m MockMovieModel) GetStats(newest int) (MovieStats, error) {
+	return MovieStats{}, nil
+}
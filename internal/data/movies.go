@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/lib/pq"
@@ -12,16 +13,32 @@ import (
 )
 
 type Movie struct {
-	ID        int64     `json:"id"`
-	CreatedAt time.Time `json:"-"`
-	Title     string    `json:"title"`
-	Year      int32     `json:"year,omitempty"`
-	Runtime   Runtime   `json:"runtime,omitempty"`
-	Genres    []string  `json:"genres,omitempty"`
-	Version   int32     `json:"version"`
+	ID             int64       `json:"id"`
+	CreatedAt      time.Time   `json:"-"`
+	Title          string      `json:"title"`
+	OriginalTitle  string      `json:"original_title,omitempty"`
+	OriginalLocale string      `json:"original_locale,omitempty"`
+	Year           int32       `json:"year,omitempty"`
+	Runtime        Runtime     `json:"runtime,omitempty"`
+	Genres         []string    `json:"genres,omitempty"`
+	Version        int32       `json:"version"`
+	AverageRating  float64     `json:"average_rating"`
+	RatingCount    int32       `json:"rating_count"`
+	Highlight      string      `json:"highlight,omitempty"`
+	PosterURLs     PosterURLs  `json:"poster_urls,omitempty"`
+	ExternalIDs    ExternalIDs `json:"external_ids,omitempty"`
+	DeletedAt      *time.Time  `json:"deleted_at,omitempty"`
 }
 
-func ValidateMovie(v *validator.Validator, movie *Movie) {
+// ETag leaves out the rating aggregates on purpose, so that other users'
+// ratings don't fail an editor's If-Match.
+func (m *Movie) ETag() string {
+	return fmt.Sprintf(`"%d-%d"`, m.ID, m.Version)
+}
+
+func ValidateMovie(v *validator.Validator, movie *Movie, genres GenreTaxonomy) {
+	canonicalizeGenres(v, "genres", movie.Genres, genres)
+
 	v.Check(movie.Title != "", "title", "must be provided")
 	v.Check(len(movie.Title) <= 500, "title", "must not be more than 500 bytes long")
 	v.Check(movie.Year != 0, "year", "must be provided")
@@ -33,27 +50,49 @@ func ValidateMovie(v *validator.Validator, movie *Movie) {
 	v.Check(len(movie.Genres) >= 1, "genres", "must contain at least 1 genre")
 	v.Check(len(movie.Genres) <= 5, "genres", "must not contain more than 5 genres")
 	v.Check(validator.Unique(movie.Genres), "genres", "must not contain duplicate values")
+
+	if movie.OriginalLocale != "" {
+		movie.OriginalLocale = NormalizeLocale(movie.OriginalLocale)
+		ValidateLocale(v, "original_locale", movie.OriginalLocale)
+	}
+
+	ValidateExternalIDs(v, movie.ExternalIDs)
+}
+
+const MaxMovieIDs = 100
+
+func ValidateMovieIDs(v *validator.Validator, ids []int64) {
+	v.Check(len(ids) >= 1, "ids", "must contain at least 1 id")
+	v.Check(len(ids) <= MaxMovieIDs, "ids", "must not contain more than 100 ids")
+	v.Check(validator.Unique(ids), "ids", "must not contain duplicate values")
+
+	for _, id := range ids {
+		if id < 1 {
+			v.AddError("ids", "must only contain positive integers")
+			break
+		}
+	}
 }
 
 type MovieModel struct {
 	DB *sql.DB
 }
 
-func (m MovieModel) GetMany(title string, genres []string, lp ListParams) ([]*Movie, Metadata, error) {
+func (m MovieModel) GetMany(f MovieFilters, lp ListParams) ([]*Movie, Metadata, error) {
+	conditions, args := f.where()
+
 	query := fmt.Sprintf(`
-	SELECT count(*) OVER(), id, title, year, runtime, genres, created_at, version
+	SELECT count(*) OVER(), id, title, year, runtime, genres, created_at, version, average_rating, rating_count, poster_urls, original_locale, %s, %s AS highlight, %s AS relevance
 	 FROM MOVIES
-	WHERE 1 = 1
-	  AND (to_tsvector('simple', title) @@ plainto_tsquery('simple', $1) OR $1 = '')
-	  AND (genres @> $2 OR $2 = '{}')
-	ORDER BY %s %s, id ASC
-	LIMIT $3 OFFSET $4
-	`, lp.sortColumn(), lp.sortDirection())
+	WHERE 1 = 1 %s
+	ORDER BY %s, id ASC
+	LIMIT $%d OFFSET $%d
+	`, externalIDsColumn("movies"), f.headline(), movieRelevance(f, lp), conditions, movieOrderBy(lp), len(args)+1, len(args)+2)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	args := []interface{}{title, pq.Array(genres), lp.limit(), lp.offset()}
+	args = append(args, lp.limit(), lp.offset())
 
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -66,6 +105,7 @@ func (m MovieModel) GetMany(title string, genres []string, lp ListParams) ([]*Mo
 
 	for rows.Next() {
 		var movie Movie
+		var relevance float64
 
 		err := rows.Scan(
 			&totalRecords,
@@ -76,6 +116,13 @@ func (m MovieModel) GetMany(title string, genres []string, lp ListParams) ([]*Mo
 			pq.Array(&movie.Genres),
 			&movie.CreatedAt,
 			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+			&movie.Highlight,
+			&relevance,
 		)
 		if err != nil {
 			return nil, Metadata{}, err
@@ -93,21 +140,367 @@ func (m MovieModel) GetMany(title string, genres []string, lp ListParams) ([]*Mo
 	return movies, metadata, nil
 }
 
-func (m MovieModel) Insert(movie *Movie) error {
-	query := `INSERT INTO movies (title, year, runtime, genres) 
-	VALUES ($1, $2, $3, $4) 
+func (m MovieModel) GetManyByCursor(f MovieFilters, lp ListParams) ([]*Movie, CursorMetadata, error) {
+	conditions, args := f.where()
+
+	query := fmt.Sprintf(`
+	SELECT id, title, year, runtime, genres, created_at, version, average_rating, rating_count, poster_urls, original_locale, %[9]s, %[1]s AS highlight
+	 FROM MOVIES
+	WHERE 1 = 1 %[2]s
+	  AND ($%[6]d = 0 OR %[3]s %[5]s $%[7]d OR (%[3]s = $%[7]d AND id > $%[6]d))
+	ORDER BY %[3]s %[4]s, id ASC
+	LIMIT $%[8]d
+	`, f.headline(), conditions, lp.sortColumn(), lp.sortDirection(), lp.keysetOperator(),
+		len(args)+1, len(args)+2, len(args)+3, externalIDsColumn("movies"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var afterID int64
+	var afterValue interface{}
+
+	if c, ok := lp.after(); ok {
+		afterID = c.ID
+		afterValue = c.Value
+	}
+
+	args = append(args, afterID, afterValue, lp.limit()+1)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, CursorMetadata{}, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.CreatedAt,
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+			&movie.Highlight,
+		)
+		if err != nil {
+			return nil, CursorMetadata{}, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, CursorMetadata{}, err
+	}
+
+	metadata := CursorMetadata{PageSize: lp.PageSize}
+
+	if len(movies) > lp.limit() {
+		movies = movies[:lp.limit()]
+		last := movies[len(movies)-1]
+
+		metadata.NextCursor = encodeCursor(cursor{
+			Sort:  lp.Sort,
+			Value: movieSortValue(last, lp.sortColumn()),
+			ID:    last.ID,
+		})
+	}
+
+	return movies, metadata, nil
+}
+
+func (m MovieModel) Export(f MovieFilters, lp ListParams, fn func(*Movie) error) error {
+	conditions, args := f.where()
+
+	query := fmt.Sprintf(`
+	SELECT id, title, year, runtime, genres, created_at, version, average_rating, rating_count, poster_urls, original_locale, %s, %s AS relevance
+	 FROM MOVIES
+	WHERE 1 = 1 %s
+	ORDER BY %s, id ASC
+	`, externalIDsColumn("movies"), movieRelevance(f, lp), conditions, movieOrderBy(lp))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie Movie
+		var relevance float64
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.CreatedAt,
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+			&relevance,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = fn(&movie)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// HasTitleMatches reports whether the full-text title search matches any movie,
+// ignoring every other filter.
+func (m MovieModel) HasTitleMatches(f MovieFilters) (bool, error) {
+	titleOnly := MovieFilters{Title: f.Title, Language: f.Language}
+	conditions, args := titleOnly.where()
+
+	query := `
+	SELECT EXISTS (
+		SELECT 1
+		FROM movies
+		WHERE true ` + conditions + `
+	)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (m MovieModel) GetSuggestions(title string, limit int) ([]string, error) {
+	query := `
+	SELECT title
+	 FROM movies
+	WHERE $1 <% title
+	  AND deleted_at IS NULL
+	GROUP BY title
+	ORDER BY max(word_similarity($1, title)) DESC, title ASC
+	LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, title, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []string{}
+
+	for rows.Next() {
+		var suggestion string
+
+		err := rows.Scan(&suggestion)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// movieRelevance is the SQL for a movie's relevance to the title search.
+// Ranking means scoring every row against the title and its translations, so
+// it is only done when the results are sorted by relevance.
+func movieRelevance(f MovieFilters, lp ListParams) string {
+	if lp.sortColumn() != "relevance" || f.Title == "" {
+		return "0"
+	}
+	return f.rank()
+}
+
+func movieOrderBy(lp ListParams) string {
+	if lp.sortColumn() == "relevance" {
+		return "relevance DESC"
+	}
+	return lp.sortColumn() + " " + lp.sortDirection()
+}
+
+func movieSortValue(movie *Movie, column string) string {
+	switch column {
+	case "title":
+		return movie.Title
+	case "year":
+		return strconv.FormatInt(int64(movie.Year), 10)
+	case "runtime":
+		return strconv.FormatInt(int64(movie.Runtime), 10)
+	case "average_rating":
+		return strconv.FormatFloat(movie.AverageRating, 'f', 2, 64)
+	default:
+		return strconv.FormatInt(movie.ID, 10)
+	}
+}
+
+func (m MovieModel) Insert(movie *Movie, userID int64) error {
+	query := `INSERT INTO movies (title, year, runtime, genres, original_locale) 
+	VALUES ($1, $2, $3, $4, $5) 
 	RETURNING id, created_at, version`
 
 	args := []interface{}{movie.Title,
 		movie.Year,
 		movie.Runtime,
 		pq.Array(movie.Genres),
+		movie.OriginalLocale,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return m.DB.QueryRowContext(ctx, query, args...).Scan(&movie.ID, &movie.CreatedAt, &movie.Version)
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&movie.ID, &movie.CreatedAt, &movie.Version)
+	if err != nil {
+		return err
+	}
+
+	err = replaceExternalIDs(ctx, tx, movie.ID, movie.ExternalIDs)
+	if err != nil {
+		return err
+	}
+
+	err = insertRevisions(ctx, tx, []int64{movie.ID}, userID)
+	if err != nil {
+		return err
+	}
+
+	err = queueSavedSearchMatch(ctx, tx, []int64{movie.ID})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m MovieModel) InsertMany(movies []*Movie, userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// COPY can't return the new IDs, so copy into a staging table and insert
+	// from there.
+	query := `
+	CREATE TEMPORARY TABLE movies_import (
+		title text,
+		year integer,
+		runtime integer,
+		genres text[],
+		original_locale text
+	) ON COMMIT DROP`
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("movies_import", "title", "year", "runtime", "genres", "original_locale"))
+	if err != nil {
+		return err
+	}
+
+	for _, movie := range movies {
+		_, err = stmt.ExecContext(ctx, movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.OriginalLocale)
+		if err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		stmt.Close()
+		return err
+	}
+
+	err = stmt.Close()
+	if err != nil {
+		return err
+	}
+
+	query = `
+	INSERT INTO movies (title, year, runtime, genres, original_locale)
+	SELECT title, year, runtime, genres, original_locale
+	FROM movies_import
+	RETURNING id`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	err = insertRevisions(ctx, tx, ids, userID)
+	if err != nil {
+		return err
+	}
+
+	err = queueSavedSearchMatch(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (m MovieModel) Get(id int64) (*Movie, error) {
@@ -115,9 +508,9 @@ func (m MovieModel) Get(id int64) (*Movie, error) {
 		return nil, ErrRecordNotFound
 	}
 
-	query := `SELECT id, created_at, title, year, runtime, genres, version 
+	query := `SELECT id, created_at, title, year, runtime, genres, version, average_rating, rating_count, poster_urls, original_locale, ` + externalIDsColumn("movies") + `
 	FROM movies 
-	WHERE id = $1`
+	WHERE id = $1 AND deleted_at IS NULL`
 
 	var movie Movie
 
@@ -132,6 +525,11 @@ func (m MovieModel) Get(id int64) (*Movie, error) {
 		&movie.Runtime,
 		pq.Array(&movie.Genres),
 		&movie.Version,
+		&movie.AverageRating,
+		&movie.RatingCount,
+		&movie.PosterURLs,
+		&movie.OriginalLocale,
+		&movie.ExternalIDs,
 	)
 	if err != nil {
 		switch {
@@ -146,10 +544,98 @@ func (m MovieModel) Get(id int64) (*Movie, error) {
 	return &movie, nil
 }
 
-func (m MovieModel) Update(movie *Movie) error {
+func (m MovieModel) GetByIDs(ids []int64) ([]*Movie, error) {
+	query := `
+	SELECT id, created_at, title, year, runtime, genres, version, average_rating, rating_count, poster_urls, original_locale, ` + externalIDsColumn("movies") + `
+	FROM movies
+	WHERE id = ANY($1) AND deleted_at IS NULL
+	ORDER BY array_position($1, id)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movies := []*Movie{}
+
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movies, nil
+}
+
+func (m MovieModel) MatchFilters(ids []int64, f MovieFilters) ([]int64, error) {
+	conditions, args := f.where()
+	args = append(args, pq.Array(ids))
+
+	query := fmt.Sprintf(`
+	SELECT id
+	FROM movies
+	WHERE id = ANY($%d) `, len(args)) + conditions + `
+	ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matched := []int64{}
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		matched = append(matched, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matched, nil
+}
+
+func (m MovieModel) Update(movie *Movie, userID int64) error {
 	query := `UPDATE MOVIES 
-	SET title = $2, year = $3, runtime = $4, genres = $5, version = version + 1 
-	WHERE id = $1 and version = $6 
+	SET title = $2, year = $3, runtime = $4, genres = $5, poster_urls = $6, original_locale = $7, version = version + 1 
+	WHERE id = $1 and version = $8 AND deleted_at IS NULL
 	RETURNING version`
 
 	args := []interface{}{
@@ -158,13 +644,21 @@ func (m MovieModel) Update(movie *Movie) error {
 		movie.Year,
 		movie.Runtime,
 		pq.Array(movie.Genres),
+		movie.PosterURLs,
+		movie.OriginalLocale,
 		movie.Version,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&movie.Version)
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&movie.Version)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -174,22 +668,60 @@ func (m MovieModel) Update(movie *Movie) error {
 		}
 	}
 
+	err = replaceExternalIDs(ctx, tx, movie.ID, movie.ExternalIDs)
+	if err != nil {
+		return err
+	}
+
+	err = insertRevisions(ctx, tx, []int64{movie.ID}, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetPoster neither bumps the version nor records a revision, as the poster
+// isn't part of the movie's history.
+func (m MovieModel) SetPoster(id int64, previous, urls PosterURLs) error {
+	query := `UPDATE movies
+	SET poster_urls = $3
+	WHERE id = $1 AND poster_urls = $2 AND deleted_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, previous, urls)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrEditConflict
+	}
+
 	return nil
 }
 
-func (m MovieModel) Delete(id int64) error {
+// Delete with a zero version skips the version check.
+func (m MovieModel) Delete(id int64, version int32) error {
 	if id < 1 {
 		return ErrRecordNotFound
 	}
 
-	query := `DELETE 
-	FROM MOVIES 
-	WHERE id = $1`
+	query := `UPDATE MOVIES 
+	SET deleted_at = NOW() 
+	WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, id)
+	result, err := m.DB.ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
@@ -200,26 +732,205 @@ func (m MovieModel) Delete(id int64) error {
 	}
 
 	if rowsAffected == 0 {
+		if version != 0 {
+			return ErrEditConflict
+		}
 		return ErrRecordNotFound
 	}
 
 	return nil
 }
 
+func (m MovieModel) GetDeleted(lp ListParams) ([]*Movie, Metadata, error) {
+	query := fmt.Sprintf(`
+	SELECT count(*) OVER(), id, title, year, runtime, genres, created_at, version, average_rating, rating_count, poster_urls, original_locale, %s, deleted_at
+	 FROM MOVIES
+	WHERE deleted_at IS NOT NULL
+	ORDER BY %s %s, id ASC
+	LIMIT $1 OFFSET $2
+	`, externalIDsColumn("movies"), lp.sortColumn(), lp.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, lp.limit(), lp.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	movies := []*Movie{}
+
+	for rows.Next() {
+		var movie Movie
+
+		err := rows.Scan(
+			&totalRecords,
+			&movie.ID,
+			&movie.Title,
+			&movie.Year,
+			&movie.Runtime,
+			pq.Array(&movie.Genres),
+			&movie.CreatedAt,
+			&movie.Version,
+			&movie.AverageRating,
+			&movie.RatingCount,
+			&movie.PosterURLs,
+			&movie.OriginalLocale,
+			&movie.ExternalIDs,
+			&movie.DeletedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		movies = append(movies, &movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := getMetadata(totalRecords, lp.Page, lp.PageSize)
+
+	return movies, metadata, nil
+}
+
+func (m MovieModel) Restore(id int64) (*Movie, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `UPDATE MOVIES 
+	SET deleted_at = NULL 
+	WHERE id = $1 AND deleted_at IS NOT NULL 
+	RETURNING id, created_at, title, year, runtime, genres, version, average_rating, rating_count, poster_urls, original_locale, ` + externalIDsColumn("movies")
+
+	var movie Movie
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&movie.ID,
+		&movie.CreatedAt,
+		&movie.Title,
+		&movie.Year,
+		&movie.Runtime,
+		pq.Array(&movie.Genres),
+		&movie.Version,
+		&movie.AverageRating,
+		&movie.RatingCount,
+		&movie.PosterURLs,
+		&movie.OriginalLocale,
+		&movie.ExternalIDs,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &movie, nil
+}
+
+func (m MovieModel) PurgeDeleted(retention time.Duration) ([]PosterURLs, error) {
+	query := `
+	WITH purged AS (
+		DELETE FROM movies
+		WHERE deleted_at < $1
+		RETURNING id, poster_urls
+	), revisions AS (
+		DELETE FROM movie_revisions
+		WHERE movie_id IN (SELECT id FROM purged)
+	)
+	SELECT poster_urls
+	FROM purged`
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, time.Now().Add(-retention))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posters := []PosterURLs{}
+
+	for rows.Next() {
+		var urls PosterURLs
+
+		err := rows.Scan(&urls)
+		if err != nil {
+			return nil, err
+		}
+
+		posters = append(posters, urls)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posters, nil
+}
+
 type MockMovieModel struct{}
 
-func (m MockMovieModel) GetMany(title string, genres []string, lp ListParams) ([]*Movie, Metadata, error) {
+func (m MockMovieModel) GetMany(f MovieFilters, lp ListParams) ([]*Movie, Metadata, error) {
 	return nil, Metadata{}, nil
 }
-func (m MockMovieModel) Insert(movie *Movie) error {
+func (m MockMovieModel) GetManyByCursor(f MovieFilters, lp ListParams) ([]*Movie, CursorMetadata, error) {
+	return nil, CursorMetadata{}, nil
+}
+func (m MockMovieModel) Export(f MovieFilters, lp ListParams, fn func(*Movie) error) error {
+	return nil
+}
+
+func (m MockMovieModel) HasTitleMatches(f MovieFilters) (bool, error) {
+	return true, nil
+}
+func (m MockMovieModel) GetSuggestions(title string, limit int) ([]string, error) {
+	return nil, nil
+}
+func (m MockMovieModel) Insert(movie *Movie, userID int64) error {
+	return nil
+}
+func (m MockMovieModel) InsertMany(movies []*Movie, userID int64) error {
 	return nil
 }
 func (m MockMovieModel) Get(id int64) (*Movie, error) {
 	return nil, nil
 }
-func (m MockMovieModel) Update(movie *Movie) error {
+func (m MockMovieModel) GetByIDs(ids []int64) ([]*Movie, error) {
+	return nil, nil
+}
+
+func (m MockMovieModel) MatchFilters(ids []int64, f MovieFilters) ([]int64, error) {
+	return nil, nil
+}
+
+func (m MockMovieModel) Update(movie *Movie, userID int64) error {
+	return nil
+}
+
+func (m MockMovieModel) SetPoster(id int64, previous, urls PosterURLs) error {
 	return nil
 }
-func (m MockMovieModel) Delete(id int64) error {
+func (m MockMovieModel) Delete(id int64, version int32) error {
 	return nil
 }
+func (m MockMovieModel) GetDeleted(lp ListParams) ([]*Movie, Metadata, error) {
+	return nil, Metadata{}, nil
+}
+func (m MockMovieModel) Restore(id int64) (*Movie, error) {
+	return nil, nil
+}
+func (m MockMovieModel) PurgeDeleted(retention time.Duration) ([]PosterURLs, error) {
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type PosterURLs map[string]string
+
+func (p PosterURLs) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(p)
+}
+
+func (p *PosterURLs) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into PosterURLs", src)
+	}
+
+	urls := PosterURLs{}
+
+	err := json.Unmarshal(b, &urls)
+	if err != nil {
+		return err
+	}
+
+	if len(urls) == 0 {
+		urls = nil
+	}
+
+	*p = urls
+
+	return nil
+}
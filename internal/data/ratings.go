@@ -0,0 +1,165 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+type Rating struct {
+	UserID    int64     `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
+	MovieID   int64     `json:"movie_id"`
+	Rating    int16     `json:"rating"`
+	Review    string    `json:"review,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ValidateRating(v *validator.Validator, rating *Rating) {
+	v.Check(rating.Rating >= 1, "rating", "must be at least 1")
+	v.Check(rating.Rating <= 10, "rating", "must not be more than 10")
+	v.Check(len(rating.Review) <= 10_000, "review", "must not be more than 10000 bytes long")
+}
+
+type RatingModel struct {
+	DB *sql.DB
+}
+
+func (m RatingModel) Upsert(rating *Rating) error {
+	query := `
+	INSERT INTO movie_ratings (user_id, movie_id, rating, review)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id, movie_id)
+	DO UPDATE SET rating = EXCLUDED.rating, review = EXCLUDED.review, updated_at = NOW()
+	RETURNING created_at, updated_at`
+
+	args := []interface{}{rating.UserID, rating.MovieID, rating.Rating, rating.Review}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&rating.CreatedAt, &rating.UpdatedAt)
+}
+
+func (m RatingModel) GetReviewsForMovie(movieID int64, lp ListParams) ([]*Rating, Metadata, error) {
+	query := fmt.Sprintf(`
+	SELECT count(*) OVER(), r.user_id, u.name, r.movie_id, r.rating, r.review, r.created_at, r.updated_at
+	FROM movie_ratings r
+	INNER JOIN users u
+	ON u.id = r.user_id
+	WHERE r.movie_id = $1
+	  AND r.review <> ''
+	ORDER BY r.%s %s, r.user_id ASC
+	LIMIT $2 OFFSET $3`, lp.sortColumn(), lp.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID, lp.limit(), lp.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	ratings := []*Rating{}
+
+	for rows.Next() {
+		var rating Rating
+
+		err := rows.Scan(
+			&totalRecords,
+			&rating.UserID,
+			&rating.UserName,
+			&rating.MovieID,
+			&rating.Rating,
+			&rating.Review,
+			&rating.CreatedAt,
+			&rating.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		ratings = append(ratings, &rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := getMetadata(totalRecords, lp.Page, lp.PageSize)
+
+	return ratings, metadata, nil
+}
+
+func (m RatingModel) GetLatestReviewsForMovies(movieIDs []int64, limit int) (map[int64][]*Rating, error) {
+	query := `
+	SELECT user_id, name, movie_id, rating, review, created_at, updated_at
+	FROM (
+		SELECT r.user_id, u.name, r.movie_id, r.rating, r.review, r.created_at, r.updated_at,
+			row_number() OVER (PARTITION BY r.movie_id ORDER BY r.updated_at DESC, r.user_id ASC) AS n
+		FROM movie_ratings r
+		INNER JOIN users u
+		ON u.id = r.user_id
+		WHERE r.movie_id = ANY($1)
+		  AND r.review <> ''
+	) latest
+	WHERE n <= $2
+	ORDER BY movie_id ASC, n ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(movieIDs), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make(map[int64][]*Rating)
+
+	for rows.Next() {
+		var rating Rating
+
+		err := rows.Scan(
+			&rating.UserID,
+			&rating.UserName,
+			&rating.MovieID,
+			&rating.Rating,
+			&rating.Review,
+			&rating.CreatedAt,
+			&rating.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		ratings[rating.MovieID] = append(ratings[rating.MovieID], &rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+type MockRatingModel struct{}
+
+func (m MockRatingModel) Upsert(rating *Rating) error {
+	return nil
+}
+
+func (m MockRatingModel) GetReviewsForMovie(movieID int64, lp ListParams) ([]*Rating, Metadata, error) {
+	return nil, Metadata{}, nil
+}
+
+func (m MockRatingModel) GetLatestReviewsForMovies(movieIDs []int64, limit int) (map[int64][]*Rating, error) {
+	return nil, nil
+}
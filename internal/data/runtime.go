@@ -3,36 +3,122 @@ package data
 import (
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
-var ErrInvalidRuntimeFormat = errors.New("invalid runtime format")
+var ErrInvalidRuntimeFormat = errors.New(`invalid runtime format, must be one of "102 mins", "102", "1h 42m" or ISO 8601 "PT1H42M"`)
+
+// RuntimeFormats are the formats a Runtime can be written out in:
+//
+//	mins     "102 mins"
+//	iso8601  "PT1H42M"
+//	seconds  6120
+//	human    "1h 42m"
+var RuntimeFormats = []string{"mins", "iso8601", "seconds", "human"}
+
+var (
+	runtimeNumberRX = regexp.MustCompile(`^\d+$`)
+	runtimeMinsRX   = regexp.MustCompile(`^(\d+)\s*(?:mins?|minutes?)$`)
+	runtimeHumanRX  = regexp.MustCompile(`^(?:(\d+)\s*h)?\s*(?:(\d+)\s*m)?$`)
+	runtimeISORX    = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?$`)
+)
 
 type Runtime int32
 
+// ParseRuntime rounds seconds in an ISO 8601 duration to the nearest minute.
+func ParseRuntime(s string) (Runtime, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	var hours, minutes, seconds string
+
+	if m := runtimeNumberRX.FindStringSubmatch(s); m != nil {
+		minutes = m[0]
+	} else if m := runtimeMinsRX.FindStringSubmatch(s); m != nil {
+		minutes = m[1]
+	} else if m := runtimeISORX.FindStringSubmatch(strings.ToUpper(s)); m != nil && s != "pt" {
+		hours, minutes, seconds = m[1], m[2], m[3]
+	} else if m := runtimeHumanRX.FindStringSubmatch(s); m != nil && s != "" {
+		hours, minutes = m[1], m[2]
+	} else {
+		return 0, ErrInvalidRuntimeFormat
+	}
+
+	var total int64
+
+	for _, part := range []struct {
+		value  string
+		factor int64
+	}{{hours, 3600}, {minutes, 60}, {seconds, 1}} {
+		if part.value == "" {
+			continue
+		}
+
+		n, err := strconv.ParseInt(part.value, 10, 32)
+		if err != nil {
+			return 0, ErrInvalidRuntimeFormat
+		}
+
+		total += n * part.factor
+	}
+
+	total = int64(math.Round(float64(total) / 60))
+	if total > math.MaxInt32 {
+		return 0, ErrInvalidRuntimeFormat
+	}
+
+	return Runtime(total), nil
+}
+
+// Format falls back to "mins" for an unknown format.
+func (r Runtime) Format(format string) interface{} {
+	switch format {
+	case "iso8601":
+		hours, minutes := r/60, r%60
+		switch {
+		case hours == 0:
+			return fmt.Sprintf("PT%dM", minutes)
+		case minutes == 0:
+			return fmt.Sprintf("PT%dH", hours)
+		default:
+			return fmt.Sprintf("PT%dH%dM", hours, minutes)
+		}
+	case "seconds":
+		return int64(r) * 60
+	case "human":
+		hours, minutes := r/60, r%60
+		switch {
+		case hours == 0:
+			return fmt.Sprintf("%dm", minutes)
+		case minutes == 0:
+			return fmt.Sprintf("%dh", hours)
+		default:
+			return fmt.Sprintf("%dh %dm", hours, minutes)
+		}
+	default:
+		return fmt.Sprintf("%d mins", r)
+	}
+}
+
 func (r Runtime) MarshalJSON() ([]byte, error) {
 	return []byte(strconv.Quote(fmt.Sprintf("%d mins", r))), nil
 }
 
 func (r *Runtime) UnmarshalJSON(jsonValue []byte) error {
-	unquotedJSONValue, err := strconv.Unquote(string(jsonValue))
+	s, err := strconv.Unquote(string(jsonValue))
 	if err != nil {
-		return ErrInvalidRuntimeFormat
-	}
-
-	parts := strings.Split(unquotedJSONValue, " ")
-
-	if len(parts) != 2 || parts[1] != "mins" {
-		return ErrInvalidRuntimeFormat
+		// A bare JSON number is a number of minutes.
+		s = string(jsonValue)
 	}
 
-	i, err := strconv.ParseInt(parts[0], 10, 32)
+	runtime, err := ParseRuntime(s)
 	if err != nil {
-		return ErrInvalidRuntimeFormat
+		return err
 	}
 
-	*r = Runtime(i)
+	*r = runtime
 
 	return nil
 }
@@ -0,0 +1,78 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseRuntime(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Runtime
+		wantErr error
+	}{
+		{name: "mins", input: "102 mins", want: 102},
+		{name: "single min", input: "1 min", want: 1},
+		{name: "minutes", input: "102 minutes", want: 102},
+		{name: "no space", input: "102mins", want: 102},
+		{name: "bare number", input: "102", want: 102},
+		{name: "surrounding space", input: "  102  ", want: 102},
+		{name: "hours and minutes", input: "1h 42m", want: 102},
+		{name: "hours only", input: "2h", want: 120},
+		{name: "minutes only", input: "42m", want: 42},
+		{name: "upper case", input: "1H 42M", want: 102},
+		{name: "iso 8601", input: "PT1H42M", want: 102},
+		{name: "iso 8601 lower case", input: "pt1h42m", want: 102},
+		{name: "iso 8601 hours", input: "PT2H", want: 120},
+		{name: "iso 8601 seconds round down", input: "PT1H42M29S", want: 102},
+		{name: "iso 8601 seconds round up", input: "PT1H42M30S", want: 103},
+		{name: "zero", input: "0", want: 0},
+		{name: "empty", input: "", wantErr: ErrInvalidRuntimeFormat},
+		{name: "bare iso 8601 prefix", input: "PT", wantErr: ErrInvalidRuntimeFormat},
+		{name: "negative", input: "-5 mins", wantErr: ErrInvalidRuntimeFormat},
+		{name: "decimal", input: "1.5h", wantErr: ErrInvalidRuntimeFormat},
+		{name: "words", input: "about two hours", wantErr: ErrInvalidRuntimeFormat},
+		{name: "overflow", input: "99999999999 mins", wantErr: ErrInvalidRuntimeFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRuntime(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseRuntime(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRuntime(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuntimeFormat(t *testing.T) {
+	tests := []struct {
+		runtime Runtime
+		format  string
+		want    interface{}
+	}{
+		{runtime: 102, format: "mins", want: "102 mins"},
+		{runtime: 102, format: "iso8601", want: "PT1H42M"},
+		{runtime: 120, format: "iso8601", want: "PT2H"},
+		{runtime: 42, format: "iso8601", want: "PT42M"},
+		{runtime: 102, format: "seconds", want: int64(6120)},
+		{runtime: 102, format: "human", want: "1h 42m"},
+		{runtime: 120, format: "human", want: "2h"},
+		{runtime: 42, format: "human", want: "42m"},
+		{runtime: 102, format: "unknown", want: "102 mins"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", tt.runtime, tt.format), func(t *testing.T) {
+			got := tt.runtime.Format(tt.format)
+			if got != tt.want {
+				t.Errorf("Runtime(%d).Format(%q) = %v, want %v", tt.runtime, tt.format, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var ErrDuplicateSavedSearch = errors.New("duplicate saved search")
+
+var SavedSearchFrequencies = []string{"immediate", "daily"}
+
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Frequency string    `json:"frequency"`
+	Version   int32     `json:"version"`
+}
+
+type SavedSearchAlert struct {
+	SearchID   int64
+	SearchName string
+	UserName   string
+	UserEmail  string
+	MovieID    int64
+	MovieTitle string
+	MovieYear  int32
+}
+
+func ValidateSavedSearch(v *validator.Validator, s *SavedSearch) {
+	v.Check(s.Name != "", "name", "must be provided")
+	v.Check(len(s.Name) <= 100, "name", "must not be more than 100 bytes long")
+	v.Check(len(s.Query) <= 2000, "query", "must not be more than 2000 bytes long")
+	v.Check(validator.In(s.Frequency, SavedSearchFrequencies...), "frequency", "must be immediate or daily")
+}
+
+type SavedSearchModel struct {
+	DB *sql.DB
+}
+
+func (m SavedSearchModel) Insert(s *SavedSearch) error {
+	query := `
+	INSERT INTO saved_searches (user_id, name, query, frequency)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, s.UserID, s.Name, s.Query, s.Frequency).Scan(&s.ID, &s.CreatedAt, &s.Version)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "saved_searches_user_id_name_key"`:
+			return ErrDuplicateSavedSearch
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m SavedSearchModel) GetForUser(userID int64) ([]*SavedSearch, error) {
+	query := `
+	SELECT id, user_id, created_at, name, query, frequency, version
+	FROM saved_searches
+	WHERE user_id = $1
+	ORDER BY id ASC`
+
+	return m.query(query, userID)
+}
+
+func (m SavedSearchModel) GetAll() ([]*SavedSearch, error) {
+	query := `
+	SELECT s.id, s.user_id, s.created_at, s.name, s.query, s.frequency, s.version
+	FROM saved_searches s
+	INNER JOIN users u ON u.id = s.user_id
+	WHERE u.activated
+	ORDER BY s.id ASC`
+
+	return m.query(query)
+}
+
+func (m SavedSearchModel) query(query string, args ...interface{}) ([]*SavedSearch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []*SavedSearch{}
+
+	for rows.Next() {
+		var s SavedSearch
+
+		err := rows.Scan(&s.ID, &s.UserID, &s.CreatedAt, &s.Name, &s.Query, &s.Frequency, &s.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		searches = append(searches, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (m SavedSearchModel) Get(id, userID int64) (*SavedSearch, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT id, user_id, created_at, name, query, frequency, version
+	FROM saved_searches
+	WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var s SavedSearch
+
+	err := m.DB.QueryRowContext(ctx, query, id, userID).Scan(&s.ID, &s.UserID, &s.CreatedAt, &s.Name, &s.Query, &s.Frequency, &s.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &s, nil
+}
+
+func (m SavedSearchModel) Update(s *SavedSearch) error {
+	query := `
+	UPDATE saved_searches
+	SET name = $3, query = $4, frequency = $5, version = version + 1
+	WHERE id = $1 AND user_id = $2 AND version = $6
+	RETURNING version`
+
+	args := []interface{}{s.ID, s.UserID, s.Name, s.Query, s.Frequency, s.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&s.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "saved_searches_user_id_name_key"`:
+			return ErrDuplicateSavedSearch
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m SavedSearchModel) Delete(id, userID int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+	DELETE FROM saved_searches
+	WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// queueSavedSearchMatch must run in the transaction that inserts the movies,
+// so that a movie is queued exactly when it exists.
+func queueSavedSearchMatch(ctx context.Context, tx *sql.Tx, movieIDs []int64) error {
+	query := `
+	INSERT INTO saved_search_queue (movie_id)
+	SELECT unnest($1::bigint[])`
+
+	_, err := tx.ExecContext(ctx, query, pq.Array(movieIDs))
+	return err
+}
+
+func (m SavedSearchModel) GetQueued(limit int) ([]int64, error) {
+	query := `
+	SELECT movie_id
+	FROM saved_search_queue
+	ORDER BY movie_id ASC
+	LIMIT $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+
+	for rows.Next() {
+		var id int64
+
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (m SavedSearchModel) Dequeue(movieIDs []int64) error {
+	query := `
+	DELETE FROM saved_search_queue
+	WHERE movie_id = ANY($1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(movieIDs))
+	return err
+}
+
+func (m SavedSearchModel) AddMatches(searchIDs, movieIDs []int64) error {
+	query := `
+	INSERT INTO saved_search_matches (search_id, movie_id)
+	SELECT s, mv FROM unnest($1::bigint[]) s, unnest($2::bigint[]) mv
+	ON CONFLICT DO NOTHING`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(searchIDs), pq.Array(movieIDs))
+	return err
+}
+
+func (m SavedSearchModel) GetPendingAlerts(frequency string) ([]*SavedSearchAlert, error) {
+	query := `
+	SELECT s.id, s.name, u.name, u.email, mv.id, mv.title, mv.year
+	FROM saved_search_matches sm
+	INNER JOIN saved_searches s ON s.id = sm.search_id
+	INNER JOIN users u ON u.id = s.user_id
+	INNER JOIN movies mv ON mv.id = sm.movie_id
+	WHERE sm.notified_at IS NULL
+	  AND s.frequency = $1
+	  AND u.activated
+	  AND mv.deleted_at IS NULL
+	ORDER BY u.id ASC, s.id ASC, sm.matched_at ASC, mv.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, frequency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []*SavedSearchAlert{}
+
+	for rows.Next() {
+		var a SavedSearchAlert
+
+		err := rows.Scan(&a.SearchID, &a.SearchName, &a.UserName, &a.UserEmail, &a.MovieID, &a.MovieTitle, &a.MovieYear)
+		if err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (m SavedSearchModel) MarkNotified(alerts []*SavedSearchAlert) error {
+	searchIDs := make([]int64, len(alerts))
+	movieIDs := make([]int64, len(alerts))
+
+	for i, a := range alerts {
+		searchIDs[i], movieIDs[i] = a.SearchID, a.MovieID
+	}
+
+	query := `
+	UPDATE saved_search_matches
+	SET notified_at = NOW()
+	WHERE (search_id, movie_id) IN (SELECT * FROM unnest($1::bigint[], $2::bigint[]))`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(searchIDs), pq.Array(movieIDs))
+	return err
+}
+
+type MockSavedSearchModel struct{}
+
+func (m MockSavedSearchModel) Insert(s *SavedSearch) error {
+	return nil
+}
+
+func (m MockSavedSearchModel) GetForUser(userID int64) ([]*SavedSearch, error) {
+	return nil, nil
+}
+
+func (m MockSavedSearchModel) GetAll() ([]*SavedSearch, error) {
+	return nil, nil
+}
+
+func (m MockSavedSearchModel) Get(id, userID int64) (*SavedSearch, error) {
+	return nil, nil
+}
+
+func (m MockSavedSearchModel) Update(s *SavedSearch) error {
+	return nil
+}
+
+func (m MockSavedSearchModel) Delete(id, userID int64) error {
+	return nil
+}
+
+func (m MockSavedSearchModel) GetQueued(limit int) ([]int64, error) {
+	return nil, nil
+}
+
+func (m MockSavedSearchModel) Dequeue(movieIDs []int64) error {
+	return nil
+}
+
+func (m MockSavedSearchModel) AddMatches(searchIDs, movieIDs []int64) error {
+	return nil
+}
+
+func (m MockSavedSearchModel) GetPendingAlerts(frequency string) ([]*SavedSearchAlert, error) {
+	return nil, nil
+}
+
+func (m MockSavedSearchModel) MarkNotified(alerts []*SavedSearchAlert) error {
+	return nil
+}
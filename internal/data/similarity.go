@@ -0,0 +1,226 @@
+package data
+
+import (
+	"math"
+	"sort"
+)
+
+// SimilarityScorer scores from 0 to 1, returning false when it has nothing to
+// go on for the pair.
+type SimilarityScorer interface {
+	Score(a, b *Movie) (float64, bool)
+}
+
+type FeatureScorer struct {
+	GenreWeight   float64
+	YearWeight    float64
+	RuntimeWeight float64
+}
+
+func NewFeatureScorer() FeatureScorer {
+	return FeatureScorer{GenreWeight: 0.6, YearWeight: 0.25, RuntimeWeight: 0.15}
+}
+
+func (s FeatureScorer) Score(a, b *Movie) (float64, bool) {
+	// Proximity falls to a half at a 10-year or 30-minute difference.
+	year := 1 / (1 + math.Abs(float64(a.Year-b.Year))/10)
+	runtime := 1 / (1 + math.Abs(float64(a.Runtime-b.Runtime))/30)
+
+	total := s.GenreWeight*jaccard(a.Genres, b.Genres) + s.YearWeight*year + s.RuntimeWeight*runtime
+
+	return total / (s.GenreWeight + s.YearWeight + s.RuntimeWeight), true
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, s := range a {
+		set[s] = true
+	}
+
+	intersection := 0
+	for _, s := range b {
+		if set[s] {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+type CoRating struct {
+	MovieID int64
+	OtherID int64
+	Score   float64
+}
+
+type CoRatingScorer struct {
+	scores map[[2]int64]float64
+}
+
+func NewCoRatingScorer(coRatings []CoRating) CoRatingScorer {
+	s := CoRatingScorer{scores: make(map[[2]int64]float64, len(coRatings))}
+
+	for _, c := range coRatings {
+		s.scores[[2]int64{c.MovieID, c.OtherID}] = c.Score
+	}
+
+	return s
+}
+
+func (s CoRatingScorer) Score(a, b *Movie) (float64, bool) {
+	score, ok := s.scores[[2]int64{a.ID, b.ID}]
+	if !ok {
+		score, ok = s.scores[[2]int64{b.ID, a.ID}]
+	}
+
+	return score, ok
+}
+
+type WeightedScorer struct {
+	Scorer SimilarityScorer
+	Weight float64
+}
+
+type BlendedScorer []WeightedScorer
+
+func (s BlendedScorer) Score(a, b *Movie) (float64, bool) {
+	var total, weights float64
+
+	for _, ws := range s {
+		score, ok := ws.Scorer.Score(a, b)
+		if !ok {
+			continue
+		}
+
+		total += ws.Weight * score
+		weights += ws.Weight
+	}
+
+	if weights == 0 {
+		return 0, false
+	}
+
+	return total / weights, true
+}
+
+type Neighbour struct {
+	MovieID     int64
+	NeighbourID int64
+	Score       float64
+}
+
+type SimilarMovie struct {
+	Movie
+	Similarity float64 `json:"similarity"`
+}
+
+func RankSimilar(movie *Movie, candidates []*Movie, scorer SimilarityScorer, k int) []*SimilarMovie {
+	similar := []*SimilarMovie{}
+
+	for _, candidate := range candidates {
+		if candidate.ID == movie.ID {
+			continue
+		}
+
+		score, ok := scorer.Score(movie, candidate)
+		if !ok || score <= 0 {
+			continue
+		}
+
+		similar = append(similar, &SimilarMovie{Movie: *candidate, Similarity: score})
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Similarity != similar[j].Similarity {
+			return similar[i].Similarity > similar[j].Similarity
+		}
+		return similar[i].ID < similar[j].ID
+	})
+
+	if len(similar) > k {
+		similar = similar[:k]
+	}
+
+	return similar
+}
+
+const genreWindow = 100
+
+// ComputeNeighbours only compares a movie with the genreWindow movies either
+// side of it by year in each of its genres, plus those it is co-rated with.
+func ComputeNeighbours(movies []*Movie, coRatings []CoRating, scorer SimilarityScorer, k int) []Neighbour {
+	byID := make(map[int64]*Movie, len(movies))
+	byGenre := make(map[string][]*Movie)
+	for _, movie := range movies {
+		byID[movie.ID] = movie
+		for _, genre := range movie.Genres {
+			byGenre[genre] = append(byGenre[genre], movie)
+		}
+	}
+
+	positions := make(map[string]map[int64]int, len(byGenre))
+	for genre, genreMovies := range byGenre {
+		sort.Slice(genreMovies, func(i, j int) bool {
+			if genreMovies[i].Year != genreMovies[j].Year {
+				return genreMovies[i].Year < genreMovies[j].Year
+			}
+			return genreMovies[i].ID < genreMovies[j].ID
+		})
+
+		positions[genre] = make(map[int64]int, len(genreMovies))
+		for i, movie := range genreMovies {
+			positions[genre][movie.ID] = i
+		}
+	}
+
+	coRated := make(map[int64][]*Movie)
+	for _, c := range coRatings {
+		if other, ok := byID[c.OtherID]; ok {
+			coRated[c.MovieID] = append(coRated[c.MovieID], other)
+		}
+	}
+
+	neighbours := []Neighbour{}
+
+	for _, movie := range movies {
+		seen := make(map[int64]bool)
+		candidates := []*Movie{}
+
+		add := func(candidate *Movie) {
+			if !seen[candidate.ID] {
+				seen[candidate.ID] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		for _, genre := range movie.Genres {
+			genreMovies, i := byGenre[genre], positions[genre][movie.ID]
+
+			lo, hi := i-genreWindow, i+genreWindow+1
+			if lo < 0 {
+				lo = 0
+			}
+			if hi > len(genreMovies) {
+				hi = len(genreMovies)
+			}
+
+			for _, candidate := range genreMovies[lo:hi] {
+				add(candidate)
+			}
+		}
+
+		for _, candidate := range coRated[movie.ID] {
+			add(candidate)
+		}
+
+		for _, similar := range RankSimilar(movie, candidates, scorer, k) {
+			neighbours = append(neighbours, Neighbour{MovieID: movie.ID, NeighbourID: similar.ID, Score: similar.Similarity})
+		}
+	}
+
+	return neighbours
+}
@@ -0,0 +1,161 @@
+package data
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFeatureScorer(t *testing.T) {
+	base := &Movie{ID: 1, Year: 2000, Runtime: 100, Genres: []string{"drama", "crime"}}
+
+	tests := []struct {
+		name  string
+		other *Movie
+		want  float64
+	}{
+		{name: "identical", other: &Movie{ID: 2, Year: 2000, Runtime: 100, Genres: []string{"crime", "drama"}}, want: 1},
+		{name: "ten years apart", other: &Movie{ID: 2, Year: 2010, Runtime: 100, Genres: []string{"drama", "crime"}}, want: 0.6 + 0.25*0.5 + 0.15},
+		{name: "thirty minutes apart", other: &Movie{ID: 2, Year: 2000, Runtime: 130, Genres: []string{"drama", "crime"}}, want: 0.6 + 0.25 + 0.15*0.5},
+		{name: "half the genres", other: &Movie{ID: 2, Year: 2000, Runtime: 100, Genres: []string{"drama"}}, want: 0.6*0.5 + 0.25 + 0.15},
+		{name: "overlapping genres", other: &Movie{ID: 2, Year: 2000, Runtime: 100, Genres: []string{"drama", "war"}}, want: 0.6/3 + 0.25 + 0.15},
+		{name: "no genres in common", other: &Movie{ID: 2, Year: 2000, Runtime: 100, Genres: []string{"horror"}}, want: 0.25 + 0.15},
+	}
+
+	scorer := NewFeatureScorer()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := scorer.Score(base, tt.other)
+			if !ok {
+				t.Fatal("got no score")
+			}
+			if !almostEqual(got, tt.want) {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+
+			reversed, _ := scorer.Score(tt.other, base)
+			if !almostEqual(reversed, got) {
+				t.Errorf("Score() is not symmetric: %v and %v", got, reversed)
+			}
+		})
+	}
+}
+
+func TestBlendedScorer(t *testing.T) {
+	a := &Movie{ID: 1, Year: 2000, Runtime: 100, Genres: []string{"drama"}}
+	b := &Movie{ID: 2, Year: 2000, Runtime: 100, Genres: []string{"horror"}}
+	c := &Movie{ID: 3, Year: 2000, Runtime: 100, Genres: []string{"drama"}}
+
+	coRatings := NewCoRatingScorer([]CoRating{{MovieID: 1, OtherID: 2, Score: 0.8}})
+
+	tests := []struct {
+		name   string
+		scorer SimilarityScorer
+		a, b   *Movie
+		want   float64
+		wantOK bool
+	}{
+		{name: "co-rating", scorer: coRatings, a: a, b: b, want: 0.8, wantOK: true},
+		{name: "co-rating either way round", scorer: coRatings, a: b, b: a, want: 0.8, wantOK: true},
+		{name: "not co-rated", scorer: coRatings, a: a, b: c, wantOK: false},
+		{
+			name:   "blended",
+			scorer: BlendedScorer{{Scorer: NewFeatureScorer(), Weight: 1}, {Scorer: coRatings, Weight: 3}},
+			a:      a,
+			b:      b,
+			want:   (0.4 + 3*0.8) / 4,
+			wantOK: true,
+		},
+		{
+			name:   "blended falls back to features",
+			scorer: BlendedScorer{{Scorer: NewFeatureScorer(), Weight: 1}, {Scorer: coRatings, Weight: 3}},
+			a:      a,
+			b:      c,
+			want:   1,
+			wantOK: true,
+		},
+		{
+			name:   "blended with nothing to go on",
+			scorer: BlendedScorer{{Scorer: coRatings, Weight: 1}},
+			a:      a,
+			b:      c,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.scorer.Score(tt.a, tt.b)
+			if ok != tt.wantOK {
+				t.Fatalf("Score() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !almostEqual(got, tt.want) {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeNeighbours(t *testing.T) {
+	movies := func() []*Movie {
+		return []*Movie{
+			{ID: 1, Year: 2000, Runtime: 100, Genres: []string{"drama"}},
+			{ID: 2, Year: 2001, Runtime: 100, Genres: []string{"drama"}},
+			{ID: 3, Year: 1950, Runtime: 100, Genres: []string{"drama"}},
+			{ID: 4, Year: 2000, Runtime: 100, Genres: []string{"horror"}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		coRatings []CoRating
+		k         int
+		want      [][2]int64
+	}{
+		{
+			name: "closest in genre",
+			k:    1,
+			want: [][2]int64{{1, 2}, {2, 1}, {3, 1}},
+		},
+		{
+			name: "up to k each",
+			k:    2,
+			want: [][2]int64{{1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}},
+		},
+		{
+			name:      "co-rated across genres",
+			coRatings: []CoRating{{MovieID: 4, OtherID: 1, Score: 0.9}},
+			k:         1,
+			want:      [][2]int64{{1, 2}, {2, 1}, {3, 1}, {4, 1}},
+		},
+		{
+			name:      "co-rated movie that no longer exists",
+			coRatings: []CoRating{{MovieID: 4, OtherID: 99, Score: 0.9}},
+			k:         1,
+			want:      [][2]int64{{1, 2}, {2, 1}, {3, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeNeighbours(movies(), tt.coRatings, NewFeatureScorer(), tt.k)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d neighbours %v, want %d", len(got), got, len(tt.want))
+			}
+
+			for i, n := range got {
+				if [2]int64{n.MovieID, n.NeighbourID} != tt.want[i] {
+					t.Errorf("neighbour %d = %d -> %d, want %d -> %d", i, n.MovieID, n.NeighbourID, tt.want[i][0], tt.want[i][1])
+				}
+				if n.Score <= 0 || n.Score > 1 {
+					t.Errorf("neighbour %d score = %v, want within (0, 1]", i, n.Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"greenlight.aenkas.org/internal/validator"
+)
+
+var LocaleRX = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+type MovieTranslation struct {
+	MovieID int64  `json:"movie_id"`
+	Locale  string `json:"locale"`
+	Title   string `json:"title"`
+}
+
+func ValidateLocale(v *validator.Validator, key, locale string) {
+	v.Check(validator.Matches(locale, LocaleRX), key, "must be a language code such as \"de\" or \"pt-BR\"")
+}
+
+func ValidateMovieTranslation(v *validator.Validator, t *MovieTranslation) {
+	ValidateLocale(v, "locale", t.Locale)
+	v.Check(t.Title != "", "title", "must be provided")
+	v.Check(len(t.Title) <= 500, "title", "must not be more than 500 bytes long")
+}
+
+func NormalizeLocale(locale string) string {
+	language, region, found := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !found {
+		return strings.ToLower(language)
+	}
+	return strings.ToLower(language) + "-" + strings.ToUpper(region)
+}
+
+func LocaleLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// BestTranslation returns nil when the original locale fits the client before
+// any translation does.
+func BestTranslation(translations []*MovieTranslation, original string, locales []string) *MovieTranslation {
+	for _, locale := range locales {
+		if original != "" && LocaleLanguage(original) == LocaleLanguage(locale) {
+			return nil
+		}
+
+		for _, t := range translations {
+			if t.Locale == locale {
+				return t
+			}
+		}
+
+		for _, t := range translations {
+			if LocaleLanguage(t.Locale) == LocaleLanguage(locale) {
+				return t
+			}
+		}
+	}
+
+	return nil
+}
+
+type MovieTranslationModel struct {
+	DB *sql.DB
+}
+
+func (m MovieTranslationModel) GetForMovie(movieID int64) ([]*MovieTranslation, error) {
+	query := `
+	SELECT movie_id, locale, title
+	FROM movie_translations
+	WHERE movie_id = $1
+	ORDER BY locale ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*MovieTranslation{}
+
+	for rows.Next() {
+		var t MovieTranslation
+
+		err := rows.Scan(&t.MovieID, &t.Locale, &t.Title)
+		if err != nil {
+			return nil, err
+		}
+
+		translations = append(translations, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (m MovieTranslationModel) GetForMovies(movieIDs []int64, languages []string) (map[int64][]*MovieTranslation, error) {
+	query := `
+	SELECT movie_id, locale, title
+	FROM movie_translations
+	WHERE movie_id = ANY($1)
+	  AND split_part(locale, '-', 1) = ANY($2)
+	ORDER BY movie_id ASC, locale ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(movieIDs), pq.Array(languages))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[int64][]*MovieTranslation)
+
+	for rows.Next() {
+		var t MovieTranslation
+
+		err := rows.Scan(&t.MovieID, &t.Locale, &t.Title)
+		if err != nil {
+			return nil, err
+		}
+
+		translations[t.MovieID] = append(translations[t.MovieID], &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (m MovieTranslationModel) Upsert(t *MovieTranslation) error {
+	query := `
+	INSERT INTO movie_translations (movie_id, locale, title)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (movie_id, locale)
+	DO UPDATE SET title = EXCLUDED.title`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, t.MovieID, t.Locale, t.Title)
+	return err
+}
+
+func (m MovieTranslationModel) Delete(movieID int64, locale string) error {
+	query := `
+	DELETE FROM movie_translations
+	WHERE movie_id = $1 AND locale = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, movieID, locale)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+type MockMovieTranslationModel struct{}
+
+func (m MockMovieTranslationModel) GetForMovie(movieID int64) ([]*MovieTranslation, error) {
+	return nil, nil
+}
+
+func (m MockMovieTranslationModel) GetForMovies(movieIDs []int64, languages []string) (map[int64][]*MovieTranslation, error) {
+	return nil, nil
+}
+
+func (m MockMovieTranslationModel) Upsert(t *MovieTranslation) error {
+	return nil
+}
+
+func (m MockMovieTranslationModel) Delete(movieID int64, locale string) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package data
+
+import "testing"
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "de", want: "de"},
+		{input: "DE", want: "de"},
+		{input: "pt-br", want: "pt-BR"},
+		{input: "PT_br", want: "pt-BR"},
+		{input: " en-GB ", want: "en-GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeLocale(tt.input); got != tt.want {
+				t.Errorf("NormalizeLocale(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestTranslation(t *testing.T) {
+	translations := []*MovieTranslation{
+		{Locale: "de", Title: "Das Boot"},
+		{Locale: "pt-BR", Title: "O Barco"},
+		{Locale: "pt-PT", Title: "A Barca"},
+	}
+
+	tests := []struct {
+		name     string
+		original string
+		locales  []string
+		want     string
+	}{
+		{name: "exact", locales: []string{"pt-PT"}, want: "A Barca"},
+		{name: "language only", locales: []string{"de-AT"}, want: "Das Boot"},
+		{name: "exact beats language", locales: []string{"pt-BR"}, want: "O Barco"},
+		{name: "first preference wins", locales: []string{"fr", "de", "pt-BR"}, want: "Das Boot"},
+		{name: "no match", locales: []string{"fr", "it"}, want: ""},
+		{name: "no locales", locales: nil, want: ""},
+		{name: "original preferred", original: "en", locales: []string{"en-US", "de"}, want: ""},
+		{name: "translation before original", original: "en", locales: []string{"de", "en"}, want: "Das Boot"},
+		{name: "original unknown", original: "", locales: []string{"de"}, want: "Das Boot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BestTranslation(translations, tt.original, tt.locales)
+
+			var title string
+			if got != nil {
+				title = got.Title
+			}
+
+			if title != tt.want {
+				t.Errorf("BestTranslation(%q, %v) = %q, want %q", tt.original, tt.locales, title, tt.want)
+			}
+		})
+	}
+}
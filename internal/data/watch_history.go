@@ -0,0 +1,219 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"greenlight.aenkas.org/internal/validator"
+)
+
+type WatchEntry struct {
+	ID        int64     `json:"id"`
+	MovieID   int64     `json:"movie_id"`
+	Title     string    `json:"title,omitempty"`
+	WatchedAt time.Time `json:"watched_at"`
+	UserID    int64     `json:"-"`
+}
+
+type GenreStat struct {
+	Genre   string  `json:"genre"`
+	Count   int     `json:"count"`
+	Runtime Runtime `json:"runtime"`
+}
+
+type WatchSummary struct {
+	TotalWatched    int         `json:"total_watched"`
+	TotalRuntime    Runtime     `json:"total_runtime"`
+	FavouriteGenres []GenreStat `json:"favourite_genres"`
+	CurrentStreak   int         `json:"current_streak_days"`
+	LongestStreak   int         `json:"longest_streak_days"`
+}
+
+func ValidateWatchEntry(v *validator.Validator, entry *WatchEntry) {
+	v.Check(entry.MovieID > 0, "movie_id", "must be provided")
+	v.Check(!entry.WatchedAt.After(time.Now()), "watched_at", "must not be in the future")
+}
+
+type WatchHistoryModel struct {
+	DB *sql.DB
+}
+
+func (m WatchHistoryModel) Insert(entry *WatchEntry) error {
+	query := `
+	INSERT INTO watch_history (user_id, movie_id, watched_at)
+	VALUES ($1, $2, $3)
+	RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, entry.UserID, entry.MovieID, entry.WatchedAt).Scan(&entry.ID)
+}
+
+func (m WatchHistoryModel) GetForUser(userID int64, lp ListParams) ([]*WatchEntry, Metadata, error) {
+	query := fmt.Sprintf(`
+	SELECT count(*) OVER(), h.id, h.movie_id, m.title, h.watched_at
+	FROM watch_history h
+	INNER JOIN movies m
+	ON m.id = h.movie_id
+	WHERE h.user_id = $1
+	ORDER BY h.%s %s, h.id ASC
+	LIMIT $2 OFFSET $3`, lp.sortColumn(), lp.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, lp.limit(), lp.offset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	entries := []*WatchEntry{}
+
+	for rows.Next() {
+		entry := WatchEntry{UserID: userID}
+
+		err := rows.Scan(&totalRecords, &entry.ID, &entry.MovieID, &entry.Title, &entry.WatchedAt)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := getMetadata(totalRecords, lp.Page, lp.PageSize)
+
+	return entries, metadata, nil
+}
+
+func (m WatchHistoryModel) GetSummary(userID int64) (*WatchSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	summary := WatchSummary{FavouriteGenres: []GenreStat{}}
+
+	query := `
+	SELECT count(*), COALESCE(sum(m.runtime), 0)
+	FROM watch_history h
+	INNER JOIN movies m
+	ON m.id = h.movie_id
+	WHERE h.user_id = $1`
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&summary.TotalWatched, &summary.TotalRuntime)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+	SELECT g, count(*), sum(m.runtime)
+	FROM watch_history h
+	INNER JOIN movies m
+	ON m.id = h.movie_id, unnest(m.genres) g
+	WHERE h.user_id = $1
+	GROUP BY g
+	ORDER BY 2 DESC, 3 DESC, g ASC
+	LIMIT 10`
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stat GenreStat
+
+		err := rows.Scan(&stat.Genre, &stat.Count, &stat.Runtime)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.FavouriteGenres = append(summary.FavouriteGenres, stat)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `
+	SELECT DISTINCT (watched_at AT TIME ZONE 'UTC')::date AS day
+	FROM watch_history
+	WHERE user_id = $1
+	ORDER BY day ASC`
+
+	dayRows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer dayRows.Close()
+
+	var days []time.Time
+
+	for dayRows.Next() {
+		var day time.Time
+
+		err := dayRows.Scan(&day)
+		if err != nil {
+			return nil, err
+		}
+
+		days = append(days, day)
+	}
+
+	if err = dayRows.Err(); err != nil {
+		return nil, err
+	}
+
+	summary.CurrentStreak, summary.LongestStreak = streaks(days, time.Now().UTC())
+
+	return &summary, nil
+}
+
+func streaks(days []time.Time, now time.Time) (current, longest int) {
+	run := 0
+
+	for i, day := range days {
+		if i > 0 && day.Sub(days[i-1]) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if len(days) > 0 {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		last := days[len(days)-1]
+
+		if last.Equal(today) || last.Equal(today.Add(-24*time.Hour)) {
+			current = run
+		}
+	}
+
+	return current, longest
+}
+
+type MockWatchHistoryModel struct{}
+
+func (m MockWatchHistoryModel) Insert(entry *WatchEntry) error {
+	return nil
+}
+
+func (m MockWatchHistoryModel) GetForUser(userID int64, lp ListParams) ([]*WatchEntry, Metadata, error) {
+	return nil, Metadata{}, nil
+}
+
+func (m MockWatchHistoryModel) GetSummary(userID int64) (*WatchSummary, error) {
+	return nil, nil
+}
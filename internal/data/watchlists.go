@@ -0,0 +1,169 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrDuplicateWatchlistItem = errors.New("duplicate watchlist item")
+
+type WatchlistItem struct {
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+	Movie    *Movie    `json:"movie"`
+}
+
+type WatchlistModel struct {
+	DB *sql.DB
+}
+
+func (m WatchlistModel) GetForUser(userID int64) ([]*WatchlistItem, error) {
+	query := `
+	SELECT w.position, w.added_at, m.id, m.title, m.year, m.runtime, m.genres, m.version
+	FROM watchlist_items w
+	INNER JOIN movies m
+	ON m.id = w.movie_id
+	WHERE w.user_id = $1
+	  AND m.deleted_at IS NULL
+	ORDER BY w.position ASC, w.added_at ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*WatchlistItem{}
+
+	for rows.Next() {
+		item := WatchlistItem{Movie: &Movie{}}
+
+		err := rows.Scan(
+			&item.Position,
+			&item.AddedAt,
+			&item.Movie.ID,
+			&item.Movie.Title,
+			&item.Movie.Year,
+			&item.Movie.Runtime,
+			pq.Array(&item.Movie.Genres),
+			&item.Movie.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (m WatchlistModel) Add(userID, movieID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Concurrent adds would otherwise take the same next position.
+	_, err = tx.ExecContext(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO watchlist_items (user_id, movie_id, position)
+	SELECT $1, $2, COALESCE(max(position), 0) + 1
+	FROM watchlist_items
+	WHERE user_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, userID, movieID)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "watchlist_items_pkey"`:
+			return ErrDuplicateWatchlistItem
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m WatchlistModel) Remove(userID, movieID int64) error {
+	query := `
+	DELETE FROM watchlist_items
+	WHERE user_id = $1 AND movie_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, movieID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Reorder puts movieIDs first and keeps any other items, such as one added
+// since the client read the list, after them in their current order.
+func (m WatchlistModel) Reorder(userID int64, movieIDs []int64) error {
+	query := `
+	UPDATE watchlist_items w
+	SET position = o.position
+	FROM (
+		SELECT i.movie_id, row_number() OVER (ORDER BY s.ord NULLS LAST, i.position, i.movie_id) AS position
+		FROM watchlist_items i
+		LEFT JOIN unnest($2::bigint[]) WITH ORDINALITY AS s(movie_id, ord) ON s.movie_id = i.movie_id
+		WHERE i.user_id = $1
+	) o
+	WHERE w.user_id = $1 AND w.movie_id = o.movie_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, pq.Array(movieIDs))
+	return err
+}
+
+type MockWatchlistModel struct{}
+
+func (m MockWatchlistModel) GetForUser(userID int64) ([]*WatchlistItem, error) {
+	return nil, nil
+}
+
+func (m MockWatchlistModel) Add(userID, movieID int64) error {
+	return nil
+}
+
+func (m MockWatchlistModel) Remove(userID, movieID int64) error {
+	return nil
+}
+
+func (m MockWatchlistModel) Reorder(userID int64, movieIDs []int64) error {
+	return nil
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS movies_title_english_idx;
+DROP INDEX IF EXISTS movies_title_russian_idx;
+DROP INDEX IF EXISTS movies_title_german_idx;
+DROP INDEX IF EXISTS movies_title_french_idx;
+DROP INDEX IF EXISTS movies_title_spanish_idx;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS movie_revisions (
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    version integer NOT NULL,
+    title text NOT NULL,
+    year integer NOT NULL,
+    runtime integer NOT NULL,
+    genres text [] NOT NULL,
+    changed_by bigint REFERENCES users ON DELETE SET NULL,
+    changed_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (movie_id, version)
+);
+INSERT INTO movie_revisions (movie_id, version, title, year, runtime, genres, changed_at)
+SELECT id, version, title, year, runtime, genres, created_at
+FROM movies ON CONFLICT DO NOTHING;
\ No newline at end of file
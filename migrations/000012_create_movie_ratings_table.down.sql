@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS movie_ratings_aggregate_trigger ON movie_ratings;
+DROP FUNCTION IF EXISTS movie_ratings_aggregate();
+DROP TABLE IF EXISTS movie_ratings;
+DROP INDEX IF EXISTS movies_average_rating_id_idx;
+ALTER TABLE movies DROP COLUMN IF EXISTS average_rating,
+    DROP COLUMN IF EXISTS rating_total,
+    DROP COLUMN IF EXISTS rating_count;
\ No newline at end of file
@@ -0,0 +1,41 @@
+ALTER TABLE movies
+ADD COLUMN IF NOT EXISTS rating_count integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_total bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS average_rating numeric(4, 2) NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS movies_average_rating_id_idx ON movies (average_rating, id);
+CREATE TABLE IF NOT EXISTS movie_ratings (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 10),
+    review text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, movie_id)
+);
+CREATE INDEX IF NOT EXISTS movie_ratings_movie_id_idx ON movie_ratings (movie_id);
+CREATE OR REPLACE FUNCTION movie_ratings_aggregate() RETURNS trigger AS $$ BEGIN IF TG_OP IN ('UPDATE', 'DELETE') THEN
+UPDATE movies
+SET rating_count = rating_count - 1,
+    rating_total = rating_total - OLD.rating,
+    average_rating = COALESCE(
+        (rating_total - OLD.rating)::numeric / NULLIF(rating_count - 1, 0),
+        0
+    )
+WHERE id = OLD.movie_id;
+END IF;
+IF TG_OP IN ('INSERT', 'UPDATE') THEN
+UPDATE movies
+SET rating_count = rating_count + 1,
+    rating_total = rating_total + NEW.rating,
+    average_rating = (rating_total + NEW.rating)::numeric / (rating_count + 1)
+WHERE id = NEW.movie_id;
+END IF;
+RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER movie_ratings_aggregate_trigger
+AFTER
+INSERT
+    OR
+UPDATE OF rating
+    OR DELETE ON movie_ratings FOR EACH ROW EXECUTE FUNCTION movie_ratings_aggregate();
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS watch_history;
+DROP TABLE IF EXISTS watchlist_items;
\ No newline at end of file
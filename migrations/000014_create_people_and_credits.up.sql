@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS people (
+    id bigserial PRIMARY KEY,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    birth_year integer NOT NULL DEFAULT 0,
+    bio text NOT NULL DEFAULT '',
+    version integer NOT NULL DEFAULT 1
+);
+CREATE TABLE IF NOT EXISTS credits (
+    id bigserial PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    person_id bigint NOT NULL REFERENCES people ON DELETE CASCADE,
+    role text NOT NULL CHECK (role IN ('director', 'actor', 'writer')),
+    character text NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS credits_movie_id_idx ON credits (movie_id);
+CREATE INDEX IF NOT EXISTS credits_person_id_idx ON credits (person_id);
+INSERT INTO permissions (code)
+VALUES ('people:read'),
+    ('people:write');
+INSERT INTO users_permissions
+SELECT up.user_id, np.id
+FROM users_permissions up
+    INNER JOIN permissions p ON p.id = up.permission_id
+    INNER JOIN permissions np ON np.code = replace(p.code, 'movies:', 'people:')
+WHERE p.code IN ('movies:read', 'movies:write') ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,73 @@
+CREATE TABLE IF NOT EXISTS genres (
+    id bigserial PRIMARY KEY,
+    slug text NOT NULL UNIQUE,
+    name text NOT NULL,
+    aliases text [] NOT NULL DEFAULT '{}'
+);
+INSERT INTO genres (slug, name, aliases)
+VALUES ('action', 'Action', '{}'),
+    ('adventure', 'Adventure', '{}'),
+    ('animation', 'Animation', '{animated, cartoon}'),
+    ('biography', 'Biography', '{biopic, biographical}'),
+    ('comedy', 'Comedy', '{comedies, comedic}'),
+    ('crime', 'Crime', '{}'),
+    ('documentary', 'Documentary', '{documentaries, doc}'),
+    ('drama', 'Drama', '{dramas}'),
+    ('family', 'Family', '{kids}'),
+    ('fantasy', 'Fantasy', '{}'),
+    ('history', 'History', '{historical}'),
+    ('horror', 'Horror', '{}'),
+    ('music', 'Music', '{}'),
+    ('musical', 'Musical', '{musicals}'),
+    ('mystery', 'Mystery', '{}'),
+    ('romance', 'Romance', '{romantic, romcom, rom-com}'),
+    ('sci-fi', 'Science Fiction', '{science-fiction, scifi, sf}'),
+    ('sport', 'Sport', '{sports}'),
+    ('thriller', 'Thriller', '{thrillers, suspense}'),
+    ('war', 'War', '{}'),
+    ('western', 'Western', '{westerns}') ON CONFLICT DO NOTHING;
+-- Mirrors genreKey in internal/data/genres.go.
+CREATE FUNCTION pg_temp.genre_key(value text) RETURNS text AS $$
+SELECT trim(
+        BOTH '-'
+        FROM regexp_replace(lower(value), '[^[:alnum:]]+', '-', 'g')
+    ) $$ LANGUAGE SQL IMMUTABLE;
+-- Any existing value that doesn't match a seeded genre becomes a genre of its own,
+-- so that no movie loses a genre in the move.
+INSERT INTO genres (slug, name)
+SELECT DISTINCT k.slug,
+    initcap(replace(k.slug, '-', ' '))
+FROM (
+        SELECT pg_temp.genre_key(value) AS slug
+        FROM movies,
+            unnest(genres) value
+        UNION
+        SELECT pg_temp.genre_key(value)
+        FROM movie_revisions,
+            unnest(genres) value
+    ) k
+WHERE k.slug <> ''
+    AND NOT EXISTS (
+        SELECT 1
+        FROM genres g
+        WHERE g.slug = k.slug
+            OR k.slug = ANY(g.aliases)
+    );
+UPDATE movies
+SET genres = ARRAY(
+        SELECT g.slug
+        FROM unnest(movies.genres) WITH ORDINALITY AS u(value, n)
+            INNER JOIN genres g ON g.slug = pg_temp.genre_key(u.value)
+            OR pg_temp.genre_key(u.value) = ANY(g.aliases)
+        GROUP BY g.slug
+        ORDER BY min(u.n)
+    );
+UPDATE movie_revisions
+SET genres = ARRAY(
+        SELECT g.slug
+        FROM unnest(movie_revisions.genres) WITH ORDINALITY AS u(value, n)
+            INNER JOIN genres g ON g.slug = pg_temp.genre_key(u.value)
+            OR pg_temp.genre_key(u.value) = ANY(g.aliases)
+        GROUP BY g.slug
+        ORDER BY min(u.n)
+    );
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE movies
+ADD COLUMN IF NOT EXISTS poster_urls jsonb NOT NULL DEFAULT '{}';
\ No newline at end of file
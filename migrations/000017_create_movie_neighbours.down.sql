@@ -0,0 +1 @@
+DROP TABLE IF EXISTS movie_neighbours;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS movie_neighbours (
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    neighbour_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    score double precision NOT NULL,
+    PRIMARY KEY (movie_id, neighbour_id)
+);
\ No newline at end of file
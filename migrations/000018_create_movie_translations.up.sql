@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS movie_translations (
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    locale text NOT NULL,
+    title text NOT NULL,
+    PRIMARY KEY (movie_id, locale)
+);
+CREATE INDEX IF NOT EXISTS movie_translations_title_idx ON movie_translations USING GIN (to_tsvector('simple', title));
+CREATE INDEX IF NOT EXISTS movie_translations_title_trgm_idx ON movie_translations USING GIN (title gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS movie_redirects (
+    old_id bigint PRIMARY KEY,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS movie_redirects_movie_id_idx ON movie_redirects (movie_id);
+CREATE INDEX IF NOT EXISTS movies_normalized_title_year_idx ON movies ((trim(regexp_replace(lower(title), '[^[:alnum:]]+', ' ', 'g'))), year);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saved_search_matches;
+DROP TABLE IF EXISTS saved_searches;
\ No newline at end of file
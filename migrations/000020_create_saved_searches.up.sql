@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    name text NOT NULL,
+    query text NOT NULL,
+    frequency text NOT NULL CHECK (frequency IN ('immediate', 'daily')),
+    version integer NOT NULL DEFAULT 1,
+    UNIQUE (user_id, name)
+);
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    search_id bigint NOT NULL REFERENCES saved_searches ON DELETE CASCADE,
+    movie_id bigint NOT NULL REFERENCES movies ON DELETE CASCADE,
+    matched_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    notified_at timestamp(0) with time zone,
+    PRIMARY KEY (search_id, movie_id)
+);
+CREATE INDEX IF NOT EXISTS saved_search_matches_pending_idx ON saved_search_matches (search_id) WHERE notified_at IS NULL;
\ No newline at end of file
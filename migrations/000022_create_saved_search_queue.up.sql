@@ -0,0 +1,3 @@
+CREATE TABLE IF NOT EXISTS saved_search_queue (
+    movie_id bigint PRIMARY KEY REFERENCES movies ON DELETE CASCADE
+);
\ No newline at end of file
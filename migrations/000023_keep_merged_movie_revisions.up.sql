@@ -0,0 +1 @@
+ALTER TABLE movie_revisions DROP CONSTRAINT IF EXISTS movie_revisions_movie_id_fkey;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS original_locale;
\ No newline at end of file
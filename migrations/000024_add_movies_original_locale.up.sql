@@ -0,0 +1 @@
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS original_locale text NOT NULL DEFAULT '';
\ No newline at end of file
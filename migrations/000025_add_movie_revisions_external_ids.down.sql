@@ -0,0 +1,3 @@
+ALTER TABLE movie_revisions
+DROP COLUMN IF EXISTS external_ids,
+DROP COLUMN IF EXISTS original_locale;
\ No newline at end of file
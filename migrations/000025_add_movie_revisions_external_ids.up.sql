@@ -0,0 +1,6 @@
+ALTER TABLE movie_revisions
+ADD COLUMN IF NOT EXISTS external_ids jsonb NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS original_locale text NOT NULL DEFAULT '';
+UPDATE movie_revisions r
+SET external_ids = (SELECT COALESCE(jsonb_object_agg(source, external_id), '{}') FROM movie_external_ids WHERE movie_id = r.movie_id),
+    original_locale = COALESCE((SELECT original_locale FROM movies WHERE id = r.movie_id), '');
\ No newline at end of file